@@ -7,13 +7,12 @@ toolchain go1.21.4
 require (
 	github.com/charmbracelet/log v0.3.1
 	github.com/wangluozhe/requests v1.2.4
-	go.uber.org/ratelimit v0.3.1
+	golang.org/x/time v0.5.0
 )
 
 require (
 	github.com/andybalholm/brotli v1.1.0 // indirect
 	github.com/aymanbagabas/go-osc52/v2 v2.0.1 // indirect
-	github.com/benbjohnson/clock v1.3.5 // indirect
 	github.com/bitly/go-simplejson v0.5.1 // indirect
 	github.com/charmbracelet/lipgloss v0.10.0 // indirect
 	github.com/cloudflare/circl v1.3.7 // indirect
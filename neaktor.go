@@ -1,24 +1,27 @@
 package neaktor_api
 
 import (
-	"encoding/json"
+	"context"
 	"errors"
-	"fmt"
 	"sync"
 	"time"
 
 	"github.com/charmbracelet/log"
-	"github.com/wangluozhe/requests"
-	"go.uber.org/ratelimit"
+	"golang.org/x/time/rate"
+
+	nethttp "net/http"
+	neturl "net/url"
 
 	requrl "github.com/wangluozhe/requests/url"
 )
 
+// ApiServer is the default base url, another one can be passed with WithBaseUrl
 const ApiServer = "https://api.neaktor.com"
 const ApiGateway = ApiServer + "/v1"
 const ModelCacheTime = time.Minute * 30
 
 var ErrCodeUnknown = errors.New("UNKNOWN_ERROR")
+var ErrCode401 = errors.New("401")
 var ErrCode403 = errors.New("403")
 var ErrCode404 = errors.New("404")
 var ErrCode422 = errors.New("422")
@@ -41,10 +44,25 @@ type NeaktorErrorResponse struct {
 }
 
 type Neaktor struct {
-	apiLimiter   ratelimit.Limiter
-	httpClient   requrl.Request
-	refreshToken string
-	token        string
+	apiServer       string
+	apiGateway      string
+	apiLimiter      *rate.Limiter
+	httpDoer        IHttpDoer
+	retryPolicy     RetryPolicy
+	middlewares     []Middleware
+	metrics         IMetrics
+	tracer          ITracer
+	pageConcurrency int
+	clientId        string
+	clientSecret    string
+	refreshToken    string
+
+	tokenLock   sync.RWMutex
+	tokenSource ITokenSource
+	tokenStore  ITokenStore
+
+	pipeline      RequestHandler
+	oauthPipeline RequestHandler
 
 	log *log.Logger
 
@@ -54,92 +72,126 @@ type Neaktor struct {
 
 type INeaktor interface {
 	RefreshToken(clientId, clientSecret, refreshToken string) (err error)
+	RefreshTokenWithContext(ctx context.Context, clientId, clientSecret, refreshToken string) (err error)
 	GetModelByTitle(title string) (model IModel, err error)
+	GetModelByTitleWithContext(ctx context.Context, title string) (model IModel, err error)
 	MustGetModelByTitle(title string) (model IModel)
 	SetLogger(log *log.Logger)
 }
 
-func NewNeaktor(httpClient requrl.Request, apiToken string, apiLimit int) INeaktor {
-	return &Neaktor{
-		apiLimiter: ratelimit.New(apiLimit, ratelimit.Per(time.Minute)),
-		httpClient: httpClient,
-		token:      apiToken,
-		log:        log.WithPrefix("neaktor"),
+type NeaktorOption func(n *Neaktor)
 
-		modelCacheLock: sync.Mutex{},
-		modelCacheMap:  make(map[string]ModelCache, 0),
+// WithBaseUrl points the client to another api server, e.g. a local stand-in, a staging tenant or a recording proxy.
+// The url is the server root, the /v1 gateway and /oauth/token endpoints are resolved against it.
+func WithBaseUrl(baseUrl string) NeaktorOption {
+	return func(n *Neaktor) {
+		n.apiServer = mustParseUrl(baseUrl).String()
 	}
 }
 
-func NewNeaktorByRefreshToken(httpClient requrl.Request, refreshToken string, apiLimit int) INeaktor {
+func NewNeaktor(httpClient requrl.Request, apiToken string, apiLimit int, options ...NeaktorOption) INeaktor {
+	n := newNeaktor(httpClient, apiLimit)
+	n.tokenSource = NewStaticTokenSource(apiToken)
+
+	return n.applyOptions(options)
+}
+
+func NewNeaktorByRefreshToken(httpClient requrl.Request, refreshToken string, apiLimit int, options ...NeaktorOption) INeaktor {
+	n := newNeaktor(httpClient, apiLimit)
+	n.refreshToken = refreshToken
+
+	return n.applyOptions(options)
+}
+
+// newNeaktor returns the client with the defaults shared by the constructors, the token is set by the caller
+func newNeaktor(httpClient requrl.Request, apiLimit int) *Neaktor {
 	return &Neaktor{
-		apiLimiter:   ratelimit.New(apiLimit, ratelimit.Per(time.Minute)),
-		httpClient:   httpClient,
-		refreshToken: refreshToken,
-		log:          log.WithPrefix("neaktor"),
+		apiServer:       ApiServer,
+		apiLimiter:      newApiLimiter(apiLimit),
+		httpDoer:        NewRequestsDoer(httpClient),
+		retryPolicy:     NoRetryPolicy,
+		metrics:         nopMetrics{},
+		tracer:          nopTracer{},
+		pageConcurrency: 1,
+		log:             log.WithPrefix("neaktor"),
 
 		modelCacheLock: sync.Mutex{},
 		modelCacheMap:  make(map[string]ModelCache, 0),
 	}
 }
 
-func (n *Neaktor) SetLogger(logger *log.Logger) {
-	n.log = logger
-}
-
-func (n *Neaktor) RefreshToken(clientId, clientSecret, refreshToken string) (err error) { // FIXME временная мера из-за бага в самом неакторе, приходится таким образом доставать ключ
-	type OauthTokenResponse struct {
-		NeaktorErrorResponse
-		AccessToken  string `json:"access_token"`
-		TokenType    string `json:"token_type"`
-		RefreshToken string `json:"refresh_token"`
-		ExpiresIn    int    `json:"expires_in"`
-		Scope        string `json:"scope"`
+func (n *Neaktor) applyOptions(options []NeaktorOption) *Neaktor {
+	for _, option := range options {
+		option(n)
 	}
 
-	httpClient := n.httpClient
-
-	httpClient.Data = requrl.NewData()
-	httpClient.Data.Add("grant_type", "refresh_token")
-	httpClient.Data.Add("redirect_uri", "https://redirectUri.com")
-	httpClient.Data.Add("client_id", clientId)
-	httpClient.Data.Add("client_secret", clientSecret)
-	httpClient.Data.Add("refresh_token", refreshToken)
+	n.apiGateway = mustUrlJoinPath(n.apiServer, "v1")
 
-	response, err := requests.Post(mustUrlJoinPath(ApiServer, "oauth", "token"), &httpClient)
-	if err != nil {
-		return fmt.Errorf("/oauth/token request error: %w", err)
+	if n.tokenSource == nil {
+		n.tokenSource = newRefreshTokenSource(n, n.clientId, n.clientSecret, n.refreshToken)
 	}
 
-	if response.StatusCode >= 500 {
-		n.log.Debugf("response status code: %d", response.StatusCode)
-		return fmt.Errorf("service unavailable, code: %d", response.StatusCode)
+	if n.metrics == nil {
+		n.metrics = nopMetrics{}
 	}
-
-	var oauthTokenResponse OauthTokenResponse
-	if err := json.Unmarshal(response.Content, &oauthTokenResponse); err != nil {
-		n.log.Debugf("response code: %d, response body: %v", response.StatusCode, response.Text)
-		return fmt.Errorf("unmarshaling error: %w", err)
+	if n.tracer == nil {
+		n.tracer = nopTracer{}
 	}
 
-	if len(oauthTokenResponse.Code) > 0 {
-		return parseErrorCode(oauthTokenResponse.Code, oauthTokenResponse.Message)
+	n.pipeline = n.newPipeline(true)
+	n.oauthPipeline = n.newPipeline(false)
+
+	return n
+}
+
+// newHttpRequest creates a request to url, the endpoint is its path template used in metrics, e.g. /v1/tasks/{id}
+func (n *Neaktor) newHttpRequest(method string, endpoint string, url string) *HttpRequest {
+	request := &HttpRequest{
+		Method:   method,
+		Endpoint: endpoint,
+		Url:      url,
+		Params:   neturl.Values{},
+		Headers:  nethttp.Header{},
 	}
 
-	if len(oauthTokenResponse.AccessToken) <= 0 {
-		return ErrApiTokenIncorrect
+	return request
+}
+
+func (n *Neaktor) SetLogger(logger *log.Logger) {
+	n.log = logger
+}
+
+func (n *Neaktor) RefreshToken(clientId, clientSecret, refreshToken string) (err error) {
+	return n.RefreshTokenWithContext(context.Background(), clientId, clientSecret, refreshToken)
+}
+
+func (n *Neaktor) RefreshTokenWithContext(ctx context.Context, clientId, clientSecret, refreshToken string) (err error) {
+	ctx, span := n.startSpan(ctx, "Neaktor.RefreshToken")
+	defer func() { endSpan(span, err) }()
+
+	tokenSource := newRefreshTokenSource(n, clientId, clientSecret, refreshToken)
+	if _, err := tokenSource.Refresh(ctx, Token{}); err != nil {
+		return err
 	}
 
-	n.token = "Bearer " + oauthTokenResponse.AccessToken
+	n.setTokenSource(tokenSource)
 
 	return err
 }
 
 func (n *Neaktor) GetModelByTitle(title string) (model IModel, err error) {
+	return n.GetModelByTitleWithContext(context.Background(), title)
+}
+
+func (n *Neaktor) GetModelByTitleWithContext(ctx context.Context, title string) (model IModel, err error) {
+	ctx, span := n.startSpan(ctx, "Neaktor.GetModelByTitle", Attribute{Key: AttributeModelTitle, Value: title})
+	defer func() { endSpan(span, err) }()
+
 	type TaskModelResponseDataFields struct {
 		Id    string `json:"id"`
 		Name  string `json:"name"`
 		State string `json:"state"`
+		Type  string `json:"type"`
 	}
 	type TaskModelResponseDataStatuses struct {
 		Id     string `json:"id"`
@@ -181,42 +233,24 @@ func (n *Neaktor) GetModelByTitle(title string) (model IModel, err error) {
 
 	if cachedModel, present := n.modelCacheMap[title]; present {
 		if time.Now().Before(cachedModel.lastUpdatedAt.Add(ModelCacheTime)) {
+			n.metrics.ObserveCache(CacheModel, true)
 			return cachedModel.model, err
 		}
 
 		delete(n.modelCacheMap, title)
 	}
 
-	// request second
-
-	n.apiLimiter.Take()
-
-	httpClient := n.httpClient
+	n.metrics.ObserveCache(CacheModel, false)
 
-	httpClient.Headers = requrl.NewHeaders()
-	httpClient.Headers.Add("Authorization", n.token)
-
-	httpClient.Params = requrl.NewParams()
-	httpClient.Params.Add("size", "100")
+	// request second
 
-	response, err := requests.Get(mustUrlJoinPath(ApiGateway, "taskmodels"), &httpClient)
-	if err != nil {
-		return model, fmt.Errorf("/v1/taskmodels request error: %w", err)
-	}
+	request := n.newHttpRequest(nethttp.MethodGet, "/v1/taskmodels", mustUrlJoinPath(n.apiGateway, "taskmodels"))
 
-	if response.StatusCode >= 500 {
-		n.log.Debugf("response status code: %d", response.StatusCode)
-		return model, fmt.Errorf("service unavailable, code: %d", response.StatusCode)
-	}
+	request.Params.Add("size", "100")
 
 	var taskModelResponse TaskModelResponse
-	if err := json.Unmarshal(response.Content, &taskModelResponse); err != nil {
-		n.log.Debugf("response code: %d, response body: %v", response.StatusCode, response.Text)
-		return model, fmt.Errorf("unmarshaling error: %w", err)
-	}
-
-	if len(taskModelResponse.Code) > 0 {
-		return model, parseErrorCode(taskModelResponse.Code, taskModelResponse.Message)
+	if err := n.call(ctx, request, &taskModelResponse); err != nil {
+		return model, err
 	}
 
 	for _, item := range taskModelResponse.Data {
@@ -235,6 +269,7 @@ func (n *Neaktor) GetModelByTitle(title string) (model IModel, err error) {
 				Id:    field.Id,
 				Name:  field.Name,
 				State: field.State,
+				Type:  FieldType(field.Type),
 			}
 		}
 
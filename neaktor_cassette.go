@@ -0,0 +1,334 @@
+package neaktor_api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	nethttp "net/http"
+	neturl "net/url"
+)
+
+// CassetteRedacted replaces secrets and redacted field values in recorded cassettes
+const CassetteRedacted = "REDACTED"
+
+var ErrCassetteNotFound = errors.New("CASSETTE_NOT_FOUND")
+var ErrCassetteInteractionNotFound = errors.New("CASSETTE_INTERACTION_NOT_FOUND")
+
+type CassetteMode int
+
+const (
+	CassetteModeReplay CassetteMode = iota // answer from the cassette file, without network
+	CassetteModeRecord                     // send through the wrapped doer and keep the exchanges for Save
+)
+
+// cassetteRedactedHeaders and cassetteRedactedKeys are always redacted, in json bodies, form bodies and query params
+var cassetteRedactedHeaders = []string{"Authorization", "Cookie", "Set-Cookie"}
+var cassetteRedactedKeys = map[string]bool{
+	"access_token":  true,
+	"refresh_token": true,
+	"client_secret": true,
+}
+
+// Cassette is the fixture file, a Synthetic cassette is written by hand instead of recorded, its replay proves the client
+// agrees with the fixture, not with the api, until it is recorded over
+type Cassette struct {
+	Synthetic    bool                  `json:"synthetic,omitempty"`
+	Interactions []CassetteInteraction `json:"interactions"`
+}
+
+type CassetteInteraction struct {
+	Request  CassetteRequest  `json:"request"`
+	Response CassetteResponse `json:"response"`
+}
+
+type CassetteRequest struct {
+	Method  string         `json:"method"`
+	Url     string         `json:"url"`
+	Headers nethttp.Header `json:"headers,omitempty"`
+	Body    string         `json:"body,omitempty"`
+}
+
+type CassetteResponse struct {
+	StatusCode int            `json:"status_code"`
+	Headers    nethttp.Header `json:"headers,omitempty"`
+	Body       string         `json:"body,omitempty"`
+}
+
+// CassetteDoer records Neaktor exchanges to a json fixture and replays them in the recorded order.
+// Requests are matched by method, path, query and body, the host is ignored, so a cassette recorded against the real
+// api replays behind WithBaseUrl as well. Secrets are redacted on record, the same redaction is applied to the
+// requests being replayed, so they still match.
+type CassetteDoer struct {
+	path     string
+	mode     CassetteMode
+	httpDoer IHttpDoer
+
+	lock             sync.Mutex
+	cassette         Cassette
+	isReplayed       []bool
+	redactedFieldIds map[string]bool
+}
+
+// NewCassetteDoer loads the cassette in replay mode, in record mode httpDoer sends the requests and Save writes the file
+func NewCassetteDoer(path string, mode CassetteMode, httpDoer IHttpDoer) (cassetteDoer *CassetteDoer, err error) {
+	cassetteDoer = &CassetteDoer{
+		path:             path,
+		mode:             mode,
+		httpDoer:         httpDoer,
+		redactedFieldIds: make(map[string]bool, 0),
+	}
+
+	if mode == CassetteModeRecord {
+		return cassetteDoer, err
+	}
+
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cassetteDoer, fmt.Errorf("%w: %s", ErrCassetteNotFound, path)
+	}
+	if err != nil {
+		return cassetteDoer, fmt.Errorf("cassette file read error: %w", err)
+	}
+
+	if err := json.Unmarshal(content, &cassetteDoer.cassette); err != nil {
+		return cassetteDoer, fmt.Errorf("unmarshaling error: %w", err)
+	}
+	cassetteDoer.isReplayed = make([]bool, len(cassetteDoer.cassette.Interactions))
+
+	return cassetteDoer, err
+}
+
+// RedactFields hides the values of the task fields, e.g. passwords or personal data, they are usually known only
+// after the model is loaded
+func (d *CassetteDoer) RedactFields(fieldIds ...string) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	for _, fieldId := range fieldIds {
+		d.redactedFieldIds[fieldId] = true
+	}
+}
+
+func (d *CassetteDoer) Do(ctx context.Context, request *HttpRequest) (response *HttpResponse, err error) {
+	if err := ctx.Err(); err != nil {
+		return response, err
+	}
+
+	if d.mode == CassetteModeRecord {
+		return d.record(ctx, request)
+	}
+
+	return d.replay(request)
+}
+
+func (d *CassetteDoer) record(ctx context.Context, request *HttpRequest) (response *HttpResponse, err error) {
+	response, err = d.httpDoer.Do(ctx, request)
+	if err != nil {
+		return response, err
+	}
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	d.cassette.Interactions = append(d.cassette.Interactions, CassetteInteraction{
+		Request: d.redactRequest(request),
+		Response: CassetteResponse{
+			StatusCode: response.StatusCode,
+			Headers:    d.redactHeaders(response.Headers),
+			Body:       d.redactBody(response.Body),
+		},
+	})
+
+	return response, err
+}
+
+func (d *CassetteDoer) replay(request *HttpRequest) (response *HttpResponse, err error) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	cassetteRequest := d.redactRequest(request)
+
+	for i, interaction := range d.cassette.Interactions {
+		if d.isReplayed[i] || !isCassetteRequestMatched(interaction.Request, cassetteRequest) {
+			continue
+		}
+
+		d.isReplayed[i] = true
+
+		headers := interaction.Response.Headers.Clone()
+		if headers == nil {
+			headers = nethttp.Header{}
+		}
+
+		return &HttpResponse{
+			StatusCode: interaction.Response.StatusCode,
+			Headers:    headers,
+			Body:       []byte(interaction.Response.Body),
+		}, err
+	}
+
+	return response, fmt.Errorf("%w: %s %s", ErrCassetteInteractionNotFound, cassetteRequest.Method, cassetteRequest.Url)
+}
+
+// Save writes the recorded interactions, it does nothing in replay mode
+func (d *CassetteDoer) Save() (err error) {
+	if d.mode != CassetteModeRecord {
+		return err
+	}
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	// urls and bodies are kept readable, without \u0026 and alike
+	content := &bytes.Buffer{}
+	encoder := json.NewEncoder(content)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(d.cassette); err != nil {
+		return fmt.Errorf("marshaling error: %w", err)
+	}
+
+	if err := writeFileAtomic(d.path, content.Bytes()); err != nil {
+		return fmt.Errorf("cassette file write error: %w", err)
+	}
+
+	return err
+}
+
+// IsSynthetic reports whether the replayed cassette is written by hand, see Cassette
+func (d *CassetteDoer) IsSynthetic() bool {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	return d.cassette.Synthetic
+}
+
+// IsReplayed reports whether every recorded interaction was requested, in replay mode
+func (d *CassetteDoer) IsReplayed() bool {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	for _, isReplayed := range d.isReplayed {
+		if !isReplayed {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isCassetteRequestMatched(recorded, request CassetteRequest) bool {
+	if recorded.Method != request.Method || recorded.Body != request.Body {
+		return false
+	}
+
+	recordedUrl, err := neturl.Parse(recorded.Url)
+	if err != nil {
+		return false
+	}
+	requestUrl, err := neturl.Parse(request.Url)
+	if err != nil {
+		return false
+	}
+
+	// Encode sorts the query params by key
+	return recordedUrl.Path == requestUrl.Path && recordedUrl.Query().Encode() == requestUrl.Query().Encode()
+}
+
+func (d *CassetteDoer) redactRequest(request *HttpRequest) CassetteRequest {
+	url := mustParseUrl(request.Url)
+
+	query := url.Query()
+	for key, values := range request.Params {
+		for _, value := range values {
+			query.Add(key, value)
+		}
+	}
+	url.RawQuery = d.redactValues(query).Encode()
+
+	return CassetteRequest{
+		Method:  request.Method,
+		Url:     url.String(),
+		Headers: d.redactHeaders(request.Headers),
+		Body:    d.redactBody(request.Body),
+	}
+}
+
+func (d *CassetteDoer) redactHeaders(headers nethttp.Header) nethttp.Header {
+	if len(headers) <= 0 {
+		return nil
+	}
+
+	headers = headers.Clone()
+	for _, header := range cassetteRedactedHeaders {
+		if len(headers.Values(header)) > 0 {
+			headers.Set(header, CassetteRedacted)
+		}
+	}
+
+	return headers
+}
+
+func (d *CassetteDoer) redactValues(values neturl.Values) neturl.Values {
+	for key := range values {
+		if cassetteRedactedKeys[key] || d.redactedFieldIds[key] {
+			values[key] = []string{CassetteRedacted}
+		}
+	}
+
+	return values
+}
+
+// redactBody rewrites json bodies with sorted keys, form bodies are redacted by key, anything else is kept as is
+func (d *CassetteDoer) redactBody(body []byte) string {
+	trimmedBody := bytes.TrimSpace(body)
+	if len(trimmedBody) <= 0 {
+		return ""
+	}
+
+	// numbers are kept as is, without the float64 round trip
+	decoder := json.NewDecoder(bytes.NewReader(trimmedBody))
+	decoder.UseNumber()
+
+	var value interface{}
+	if err := decoder.Decode(&value); err == nil && !decoder.More() {
+		content := &bytes.Buffer{}
+		encoder := json.NewEncoder(content)
+		encoder.SetEscapeHTML(false)
+		if err := encoder.Encode(d.redactJson(value)); err == nil {
+			return string(bytes.TrimSpace(content.Bytes()))
+		}
+	}
+
+	if values, err := neturl.ParseQuery(string(trimmedBody)); err == nil && bytes.Contains(trimmedBody, []byte("=")) {
+		return d.redactValues(values).Encode()
+	}
+
+	return string(body)
+}
+
+// redactJson hides the secret keys and the values of fields given as {"id": "<redacted field id>", "value": ...}
+func (d *CassetteDoer) redactJson(value interface{}) interface{} {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		fieldId, isField := value["id"].(string)
+		for key, item := range value {
+			if cassetteRedactedKeys[key] || (isField && key == "value" && d.redactedFieldIds[fieldId]) {
+				value[key] = CassetteRedacted
+				continue
+			}
+			value[key] = d.redactJson(item)
+		}
+	case []interface{}:
+		for i, item := range value {
+			value[i] = d.redactJson(item)
+		}
+	}
+
+	return value
+}
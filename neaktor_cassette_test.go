@@ -0,0 +1,81 @@
+package neaktor_api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNeaktorApiCassette(t *testing.T) {
+	httpDoer := HttpDoerFunc(func(ctx context.Context, request *HttpRequest) (*HttpResponse, error) {
+		return &HttpResponse{
+			StatusCode: http.StatusOK,
+			Body:       []byte(`{"access_token":"a1c2c3e4s5s","refresh_token":"r1e2f3r4e5s6h","expires_in":3600}`),
+		}, nil
+	})
+
+	newRequest := func() *HttpRequest {
+		request := &HttpRequest{
+			Method:  http.MethodPost,
+			Url:     "https://api.neaktor.com/oauth/token",
+			Headers: http.Header{"Authorization": []string{"Bearer t1o2k3e4n5"}},
+			Body:    []byte("client_id=c1&client_secret=s1e2c3r4e5t&grant_type=refresh_token&refresh_token=r0"),
+		}
+
+		return request
+	}
+
+	// record saves a cassette with a single interaction, every subtest records its own one
+	record := func(t *testing.T) (path string) {
+		path = filepath.Join(t.TempDir(), "cassette.json")
+
+		cassetteDoer, err := NewCassetteDoer(path, CassetteModeRecord, httpDoer)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := cassetteDoer.Do(context.Background(), newRequest()); err != nil {
+			t.Fatal(err)
+		}
+		if err := cassetteDoer.Save(); err != nil {
+			t.Fatal(err)
+		}
+
+		return path
+	}
+
+	t.Run("Record", func(t *testing.T) {
+		content, err := os.ReadFile(record(t))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, secret := range []string{"t1o2k3e4n5", "s1e2c3r4e5t", "a1c2c3e4s5s", "r1e2f3r4e5s6h"} {
+			if strings.Contains(string(content), secret) {
+				t.Errorf("secret %q is not redacted:\n%s", secret, content)
+			}
+		}
+	})
+
+	t.Run("Replay", func(t *testing.T) {
+		cassetteDoer, err := NewCassetteDoer(record(t), CassetteModeReplay, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		response, err := cassetteDoer.Do(context.Background(), newRequest())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if response.StatusCode != http.StatusOK || !cassetteDoer.IsReplayed() {
+			t.Fatalf("unexpected replay: %d %s", response.StatusCode, response.Body)
+		}
+
+		if _, err := cassetteDoer.Do(context.Background(), newRequest()); !errors.Is(err, ErrCassetteInteractionNotFound) {
+			t.Fatalf("expected ErrCassetteInteractionNotFound, got %v", err)
+		}
+	})
+}
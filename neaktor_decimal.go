@@ -0,0 +1,179 @@
+package neaktor_api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Decimal is an exact decimal number kept as its text, for the amounts float64 can't hold, like 0.1 or 1234567.89.
+// The zero value is 0. Decimals compare equal with == only when their texts are equal, use Cmp to compare by value.
+type Decimal struct {
+	text string
+}
+
+var ErrDecimalInvalid = errors.New("DECIMAL_INVALID")
+
+var decimalRegexp = regexp.MustCompile(`^[+-]?(\d+\.?\d*|\.\d+)([eE][+-]?\d+)?$`)
+
+// decimalMaxExponent bounds the exponent, the expanded text of 1e999999999 would take a gigabyte
+const decimalMaxExponent = 1000
+
+// ParseDecimal parses the decimal notation to the plain one with the scale kept, "007" is "7", "00.50" is "0.50", an
+// exponent is expanded, "1.5e3" is "1500", an exponent above 1000 either way is invalid
+func ParseDecimal(text string) (decimal Decimal, err error) {
+	if !decimalRegexp.MatchString(text) {
+		return decimal, fmt.Errorf("%w: %q", ErrDecimalInvalid, text)
+	}
+
+	mantissa, exponent, isExponent := strings.Cut(strings.ToLower(text), "e")
+
+	scale := 0
+	if _, fraction, isFraction := strings.Cut(mantissa, "."); isFraction {
+		scale = len(fraction)
+	}
+	if isExponent {
+		exponentValue, err := strconv.Atoi(exponent)
+		if err != nil || exponentValue > decimalMaxExponent || exponentValue < -decimalMaxExponent {
+			return decimal, fmt.Errorf("%w: %q", ErrDecimalInvalid, text)
+		}
+		scale = max(0, scale-exponentValue)
+	}
+
+	rat, isParsed := new(big.Rat).SetString(text)
+	if !isParsed {
+		return decimal, fmt.Errorf("%w: %q", ErrDecimalInvalid, text)
+	}
+
+	return Decimal{text: rat.FloatString(scale)}, err
+}
+
+func MustParseDecimal(text string) (decimal Decimal) {
+	var err error
+	decimal, err = ParseDecimal(text)
+	if err != nil {
+		panic(err)
+	}
+
+	return decimal
+}
+
+func NewDecimalFromInt(value int64) Decimal {
+	return Decimal{text: strconv.FormatInt(value, 10)}
+}
+
+// NewDecimalFromFloat takes the shortest text that reads back as the value, 0.1 is "0.1", NaN and the infinities are
+// invalid
+func NewDecimalFromFloat(value float64) (decimal Decimal, err error) {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return decimal, fmt.Errorf("%w: %v", ErrDecimalInvalid, value)
+	}
+
+	return Decimal{text: strconv.FormatFloat(value, 'f', -1, 64)}, err
+}
+
+func MustNewDecimalFromFloat(value float64) (decimal Decimal) {
+	var err error
+	decimal, err = NewDecimalFromFloat(value)
+	if err != nil {
+		panic(err)
+	}
+
+	return decimal
+}
+
+func (d Decimal) String() string {
+	if len(d.text) <= 0 {
+		return "0"
+	}
+
+	return d.text
+}
+
+func (d Decimal) Float64() float64 {
+	value, _ := strconv.ParseFloat(d.String(), 64)
+	return value
+}
+
+func (d Decimal) Rat() *big.Rat {
+	rat, _ := new(big.Rat).SetString(d.String())
+	return rat
+}
+
+func (d Decimal) IsZero() bool {
+	return d.Rat().Sign() == 0
+}
+
+// Cmp returns -1, 0 or +1 as d is less than, equal to or greater than other
+func (d Decimal) Cmp(other Decimal) int {
+	return d.Rat().Cmp(other.Rat())
+}
+
+// Add returns the exact sum, with the larger scale of both, 0.10 + 0.2 is 0.30
+func (d Decimal) Add(other Decimal) Decimal {
+	return Decimal{text: new(big.Rat).Add(d.Rat(), other.Rat()).FloatString(max(d.scale(), other.scale()))}
+}
+
+func (d Decimal) Sub(other Decimal) Decimal {
+	return Decimal{text: new(big.Rat).Sub(d.Rat(), other.Rat()).FloatString(max(d.scale(), other.scale()))}
+}
+
+func (d Decimal) scale() int {
+	_, fraction, _ := strings.Cut(d.text, ".")
+	return len(fraction)
+}
+
+// MarshalJSON writes the json number as is
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalJSON reads a json number or a string with a number, null is 0
+func (d *Decimal) UnmarshalJSON(data []byte) (err error) {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		*d = Decimal{}
+		return err
+	}
+
+	text := string(data)
+	if strings.HasPrefix(text, `"`) {
+		if err := json.Unmarshal(data, &text); err != nil {
+			return fmt.Errorf("unmarshaling error: %w", err)
+		}
+	}
+
+	*d, err = ParseDecimal(text)
+
+	return err
+}
+
+// toDecimal converts the json and the Go numbers, and the number texts
+func toDecimal(value interface{}) (decimal Decimal, isDecimal bool) {
+	var err error
+
+	switch value := value.(type) {
+	case Decimal:
+		return value, true
+	case json.Number:
+		decimal, err = ParseDecimal(value.String())
+	case string:
+		decimal, err = ParseDecimal(value)
+	case float64:
+		decimal, err = NewDecimalFromFloat(value)
+	case int:
+		return NewDecimalFromInt(int64(value)), true
+	case int64:
+		return NewDecimalFromInt(value), true
+	default:
+		return decimal, false
+	}
+
+	return decimal, err == nil
+}
@@ -0,0 +1,92 @@
+package neaktor_api
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestNeaktorApiDecimal(t *testing.T) {
+	for text, expected := range map[string]string{
+		"1500.10": "1500.10",
+		"+7":      "7",
+		"-.5":     "-0.5",
+		"1.":      "1",
+		"1.5e3":   "1500",
+		"15e-3":   "0.015",
+		"1e1000":  "1" + strings.Repeat("0", 1000),
+		"007":     "7",
+		"00.50":   "0.50",
+		"-00":     "0",
+	} {
+		if decimal, err := ParseDecimal(text); err != nil || decimal.String() != expected {
+			t.Errorf("expected %s parsed as %s, got %v, %v", text, expected, decimal, err)
+		}
+	}
+	for _, text := range []string{"", "1/3", "1,5", "NaN", "0x10", "1e999999999", "1e-1001", "1e99999999999999999999"} {
+		if _, err := ParseDecimal(text); !errors.Is(err, ErrDecimalInvalid) {
+			t.Errorf("expected ErrDecimalInvalid for %q, got %v", text, err)
+		}
+	}
+
+	sum := Decimal{}
+	for i := 0; i < 10; i++ {
+		sum = sum.Add(MustParseDecimal("0.10"))
+	}
+	if sum.String() != "1.00" || sum.Cmp(NewDecimalFromInt(1)) != 0 {
+		t.Fatalf("expected the exact sum 1.00, got %s", sum)
+	}
+	if difference := MustParseDecimal("0.3").Sub(MustParseDecimal("0.1")).Sub(MustParseDecimal("0.2")); !difference.IsZero() {
+		t.Fatalf("expected zero, got %s", difference)
+	}
+
+	body, err := json.Marshal(CurrencyFieldValue{Value: MustParseDecimal("12345678901234567.89"), Currency: "RUB"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(body) != `{"value":12345678901234567.89,"currency":"RUB"}` {
+		t.Fatalf("unexpected currency json %s", body)
+	}
+
+	if currencyValue := MustNewCurrencyFieldValue(1500.5, "RUB"); currencyValue.Value.String() != "1500.5" || currencyValue.Float64() != 1500.5 {
+		t.Fatalf("unexpected float currency value %v", currencyValue)
+	}
+	for _, value := range []float64{math.NaN(), math.Inf(1), math.Inf(-1)} {
+		if _, err := NewCurrencyFieldValue(value, "RUB"); !errors.Is(err, ErrDecimalInvalid) {
+			t.Fatalf("expected ErrDecimalInvalid for %v, got %v", value, err)
+		}
+		if _, isDecimal := toDecimal(value); isDecimal {
+			t.Fatalf("expected %v not converted to a decimal", value)
+		}
+	}
+
+	for _, decimal := range []Decimal{MustParseDecimal("007"), MustParseDecimal("-00.50"), MustParseDecimal(".5e1"), MustNewDecimalFromFloat(1e21), {}} {
+		body, err := json.Marshal(map[string]Decimal{"value": decimal})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var value map[string]json.Number
+		if err := json.Unmarshal(body, &value); err != nil || value["value"].String() != decimal.String() {
+			t.Fatalf("expected %s marshaled as a valid json number, got %s, %v", decimal, body, err)
+		}
+	}
+
+	var currencyValue CurrencyFieldValue
+	if err := json.Unmarshal([]byte(`{"value":"0.10","currency":"USD"}`), &currencyValue); err != nil || currencyValue.Value.String() != "0.10" {
+		t.Fatalf("expected the string amount to be read, got %v, %v", currencyValue, err)
+	}
+
+	var responseField taskResponseField
+	if err := json.Unmarshal([]byte(`{"id":"f_amount","value":12345678901234567.89,"state":"EDITABLE"}`), &responseField); err != nil {
+		t.Fatal(err)
+	}
+	if value, err := decodeFieldValue(FieldTypeNumber, responseField.Value); err != nil || value != MustParseDecimal("12345678901234567.89") {
+		t.Fatalf("expected the exact number, got %v, %v", value, err)
+	}
+
+	if formatted := formatQueryValue(MustParseDecimal("1500.10")); formatted != "1500.10" {
+		t.Fatalf("expected the decimal text in the query, got %q", formatted)
+	}
+}
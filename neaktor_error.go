@@ -0,0 +1,116 @@
+package neaktor_api
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	neturl "net/url"
+)
+
+// APIError is returned for every response Neaktor rejected, either with a 4xx/5xx status code or with an error code in the body.
+// It matches the ErrCode* sentinels with errors.Is, e.g. errors.Is(err, ErrCode429).
+type APIError struct {
+	StatusCode       int
+	Code             string
+	Type             string
+	Message          string
+	ErrorDescription string
+	Method           string
+	Path             string
+}
+
+func (e *APIError) Error() string {
+	code := e.Code
+	if len(code) <= 0 {
+		code = strconv.Itoa(e.StatusCode)
+	}
+
+	message := e.Message
+	if len(message) <= 0 {
+		message = e.ErrorDescription
+	}
+
+	if len(message) <= 0 {
+		return fmt.Sprintf("%s %s: %s", e.Method, e.Path, code)
+	}
+
+	return fmt.Sprintf("%s %s: %s: %s", e.Method, e.Path, code, message)
+}
+
+// status is the http status code, or the one the error code starts with (e.g. "429 TOO_MANY_REQUESTS") when the response status is not an error
+func (e *APIError) status() int {
+	if e.StatusCode >= 400 {
+		return e.StatusCode
+	}
+
+	digits := strings.IndexFunc(e.Code, func(r rune) bool { return r < '0' || r > '9' })
+	if digits < 0 {
+		digits = len(e.Code)
+	}
+
+	status, _ := strconv.Atoi(e.Code[:digits])
+
+	return status
+}
+
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrCode401:
+		return e.status() == 401
+	case ErrCode403:
+		return e.status() == 403
+	case ErrCode404:
+		return e.status() == 404
+	case ErrCode422:
+		return e.status() == 422
+	case ErrCode429:
+		return e.status() == 429
+	case ErrCode500:
+		return e.status() >= 500
+	case ErrApiTokenIncorrect:
+		return strings.EqualFold(e.Code, ErrApiTokenIncorrect.Error())
+	case ErrCodeUnknown:
+		for _, knownError := range []error{ErrCode401, ErrCode403, ErrCode404, ErrCode422, ErrCode429, ErrCode500, ErrApiTokenIncorrect} {
+			if e.Is(knownError) {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	return false
+}
+
+// checkResponse turns a rejected response into an *APIError, nil is returned for successful responses
+func (n *Neaktor) checkResponse(request *HttpRequest, response *HttpResponse) error {
+	// the body is not always an object, an error is only looked for when it is
+	var errorResponse NeaktorErrorResponse
+	_ = json.Unmarshal(response.Body, &errorResponse)
+
+	if response.StatusCode < 400 && len(errorResponse.Code) <= 0 && len(errorResponse.Error) <= 0 {
+		return nil
+	}
+
+	n.log.Debugf("response code: %d, response body: %v", response.StatusCode, string(response.Body))
+
+	apiError := &APIError{
+		StatusCode:       response.StatusCode,
+		Code:             errorResponse.Code,
+		Type:             errorResponse.Type,
+		Message:          errorResponse.Message,
+		ErrorDescription: errorResponse.ErrorDescription,
+		Method:           request.Method,
+		Path:             request.Url,
+	}
+	if len(apiError.Code) <= 0 {
+		apiError.Code = errorResponse.Error
+	}
+	if url, err := neturl.Parse(request.Url); err == nil {
+		apiError.Path = url.Path
+	}
+
+	return apiError
+}
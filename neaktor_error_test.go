@@ -0,0 +1,49 @@
+package neaktor_api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	requrl "github.com/wangluozhe/requests/url"
+)
+
+func TestNeaktorApiError(t *testing.T) {
+	newNeaktor := func(statusCode int, body string) INeaktor {
+		httpDoer := HttpDoerFunc(func(ctx context.Context, request *HttpRequest) (*HttpResponse, error) {
+			return &HttpResponse{StatusCode: statusCode, Body: []byte(body)}, nil
+		})
+
+		return NewNeaktor(*requrl.NewRequest(), "t1o2k3e4n5", 6000, WithHttpDoer(httpDoer))
+	}
+
+	for _, testCase := range []struct {
+		name       string
+		statusCode int
+		body       string
+		target     error
+	}{
+		{"StatusWithoutBody", http.StatusNotFound, ``, ErrCode404},
+		{"StatusWithHtmlBody", http.StatusForbidden, `<html>Forbidden</html>`, ErrCode403},
+		{"Unauthorized", http.StatusUnauthorized, `{"error":"invalid_token","error_description":"expired"}`, ErrCode401},
+		{"CodeInBody", http.StatusOK, `{"code":"429 TOO_MANY_REQUESTS","message":"slow down"}`, ErrCode429},
+		{"ServerError", http.StatusBadGateway, ``, ErrCode500},
+		{"UnknownCode", http.StatusOK, `{"code":"SOMETHING_ELSE","message":"?"}`, ErrCodeUnknown},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			_, err := newNeaktor(testCase.statusCode, testCase.body).GetModelByTitle("Заказ")
+			if !errors.Is(err, testCase.target) {
+				t.Fatalf("expected %v, got: %v", testCase.target, err)
+			}
+
+			var apiError *APIError
+			if !errors.As(err, &apiError) {
+				t.Fatalf("expected *APIError, got: %T", err)
+			}
+			if apiError.Method != http.MethodGet || apiError.Path != "/v1/taskmodels" {
+				t.Fatalf("unexpected endpoint: %s %s", apiError.Method, apiError.Path)
+			}
+		})
+	}
+}
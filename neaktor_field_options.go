@@ -0,0 +1,183 @@
+package neaktor_api
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	neturl "net/url"
+)
+
+// encodeFields converts the field values to the api format and replaces the option values of the select fields by
+// the option ids, the ids are kept as is. A field is a select one by the type loaded with the model or by the cached
+// custom field, see fieldType, the values of the fields not known as select ones are sent as is.
+func (m *Model) encodeFields(ctx context.Context, fields []TaskField) (encodedFields []TaskField, err error) {
+	m.modelCustomFieldCacheLock.Lock()
+	defer m.modelCustomFieldCacheLock.Unlock()
+
+	encodedFields = make([]TaskField, 0, len(fields))
+	for _, field := range fields {
+		value := encodeFieldValue(field.Value)
+
+		field.Value, err = m.optionId(ctx, field.ModelField.Id, m.fieldType(field.ModelField.Id), value)
+		if err != nil {
+			return encodedFields, err
+		}
+
+		encodedFields = append(encodedFields, field)
+	}
+
+	return encodedFields, err
+}
+
+// decodeFields decodes the field values read from the api by the field types and sets the option values of the select
+// fields. Only the types loaded with the model and the cached custom fields are used, the api is never requested, the
+// option values not cached yet are resolved by TaskField.OptionValue. A value not matching its type is kept as read,
+// the TaskField accessors report the mismatch.
+func (m *Model) decodeFields(fields []TaskField) {
+	m.modelCustomFieldCacheLock.Lock()
+	defer m.modelCustomFieldCacheLock.Unlock()
+
+	for i, field := range fields {
+		fields[i].model = m
+
+		fieldType := m.cachedFieldType(field.ModelField)
+
+		value, err := decodeFieldValue(fieldType, field.Value)
+		if err != nil {
+			m.neaktor.log.Debugf("field %s decode error: %v", field.ModelField.Name, err)
+			continue
+		}
+		fields[i].Value = value
+
+		optionId, isString := fields[i].Value.(string)
+		if fieldType != FieldTypeSelect || !isString {
+			continue
+		}
+
+		customField, isCached := m.cachedCustomField(field.ModelField.Id)
+		if !isCached {
+			continue
+		}
+
+		for _, option := range customField.customFieldOptions {
+			if option.id == optionId {
+				fields[i].Option = option.value
+			}
+		}
+	}
+}
+
+// queryValues returns the request params of the query, with the filter values converted to the api format and the
+// option values of the select fields translated, like encodeFields does
+func (m *Model) queryValues(ctx context.Context, query *TaskQuery) (queries []neturl.Values, err error) {
+	if err := query.Err(); err != nil {
+		return queries, err
+	}
+
+	m.modelCustomFieldCacheLock.Lock()
+	defer m.modelCustomFieldCacheLock.Unlock()
+
+	encodedQuery := query.clone()
+	for i, filter := range encodedQuery.filters {
+		// the range filters aren't sent, the fetched tasks are matched against them
+		if filter.IsRange {
+			continue
+		}
+
+		value := encodeFieldValue(filter.Value)
+
+		encodedQuery.filters[i].Value, err = m.optionId(ctx, filter.FieldId, m.fieldType(filter.FieldId), value)
+		if err != nil {
+			return queries, err
+		}
+	}
+
+	return encodedQuery.values(), err
+}
+
+// optionId returns the option id of the value when the field is a select one, the cache lock must be held
+func (m *Model) optionId(ctx context.Context, fieldId string, fieldType FieldType, value interface{}) (interface{}, error) {
+	text, isString := value.(string)
+	if !isString || fieldType != FieldTypeSelect {
+		return value, nil
+	}
+
+	modelField := m.fields[fieldId]
+
+	option, isFound, err := m.findCustomFieldOption(ctx, modelField, func(option CustomFieldOption) bool {
+		return option.id == text || option.value == text
+	})
+	if err != nil {
+		return value, err
+	}
+	if !isFound {
+		return value, fmt.Errorf("%w: %s %q", ErrModelCustomFieldOptionNotFound, modelField.Name, text)
+	}
+
+	return option.id, nil
+}
+
+// fieldType returns the type of the model field by its id, the fields loaded without a type are never requested here,
+// their type is known once the custom field is cached, e.g. by GetFieldType or GetFieldOptions. The cache lock must be
+// held.
+func (m *Model) fieldType(fieldId string) FieldType {
+	return m.cachedFieldType(m.fields[fieldId])
+}
+
+// cachedFieldType returns the type loaded with the model or the type of the cached custom field, FieldTypeUnknown when
+// neither is known. The cache lock must be held.
+func (m *Model) cachedFieldType(field ModelField) (fieldType FieldType) {
+	if field.Type != FieldTypeUnknown || !m.isCustomField(field.Id) {
+		return field.Type
+	}
+
+	customField, isCached := m.cachedCustomField(field.Id)
+	if !isCached {
+		return fieldType
+	}
+
+	return customField.selectType()
+}
+
+// loadFieldType returns the custom field type, requested when it isn't cached. The cache lock must be held.
+func (m *Model) loadFieldType(ctx context.Context, field ModelField) (fieldType FieldType, err error) {
+	customField, _, err := m.loadCustomField(ctx, field, false)
+	if err != nil {
+		return fieldType, err
+	}
+
+	return customField.selectType(), err
+}
+
+// selectType returns the custom field type, a field with options is a select one whatever type the api names it
+func (c ModelCustomFieldCache) selectType() FieldType {
+	if len(c.customFieldOptions) > 0 {
+		return FieldTypeSelect
+	}
+
+	return c.fieldType
+}
+
+// cachedCustomField returns the custom field when it is cached and not stale, the cache lock must be held
+func (m *Model) cachedCustomField(fieldId string) (customField ModelCustomFieldCache, isCached bool) {
+	customField, isCached = m.modelCustomFieldCacheMap[fieldId]
+	if !isCached || !time.Now().Before(customField.lastUpdatedAt.Add(ModelCacheTime)) {
+		return customField, false
+	}
+
+	return customField, isCached
+}
+
+// isCustomField reports whether the field may be requested as a custom field, the task dates and the fields unknown to
+// the model never are
+func (m *Model) isCustomField(fieldId string) bool {
+	switch fieldId {
+	case TaskFieldStart, TaskFieldEnd, TaskFieldStatusClosedDate:
+		return false
+	}
+
+	_, present := m.fields[fieldId]
+
+	return present
+}
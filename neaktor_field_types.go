@@ -0,0 +1,132 @@
+package neaktor_api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// FieldType is the type of a model field, the values are decoded by it, see TaskField
+type FieldType string
+
+const (
+	FieldTypeUnknown  FieldType = ""          // the value is left as decoded from json
+	FieldTypeText     FieldType = "TEXT"      // string
+	FieldTypeNumber   FieldType = "NUMBER"    // Decimal, not a float64, see TaskField.Float and TaskField.Int
+	FieldTypeDate     FieldType = "DATE"      // time.Time
+	FieldTypeCurrency FieldType = "CURRENCY"  // CurrencyFieldValue
+	FieldTypeSelect   FieldType = "SELECT"    // string option id, the option value is returned by TaskField.OptionValue
+	FieldTypeUser     FieldType = "USER"      // UserRef
+	FieldTypeTaskLink FieldType = "TASK_LINK" // int task id
+)
+
+var ErrTaskFieldTypeMismatch = errors.New("TASK_FIELD_TYPE_MISMATCH")
+
+// UserRef is the value of a user field, the api sends either the user id or the user object
+type UserRef struct {
+	Id   int
+	Name string
+}
+
+// decodeFieldValue converts the json value to the Go type of the field type, null and empty values are nil. The numbers
+// come as json.Number, to keep the number and currency values exact, the numbers of the other types are float64.
+func decodeFieldValue(fieldType FieldType, value interface{}) (decodedValue interface{}, err error) {
+	if value == nil {
+		return value, err
+	}
+	if text, isString := value.(string); isString && len(text) <= 0 && fieldType != FieldTypeText {
+		return nil, err
+	}
+
+	switch fieldType {
+	case FieldTypeNumber:
+		if decimal, isDecimal := toDecimal(value); isDecimal {
+			return decimal, err
+		}
+		return value, fmt.Errorf("%w: %s %v", ErrTaskFieldTypeMismatch, fieldType, value)
+	case FieldTypeCurrency:
+		if currencyValue, isMap := value.(map[string]interface{}); isMap {
+			amount, isDecimal := toDecimal(currencyValue["value"])
+			currency, _ := currencyValue["currency"].(string)
+			if isDecimal {
+				return CurrencyFieldValue{Value: amount, Currency: currency}, err
+			}
+		}
+		return value, fmt.Errorf("%w: %s %T", ErrTaskFieldTypeMismatch, fieldType, value)
+	}
+
+	value = toFloatNumbers(value)
+
+	switch fieldType {
+	case FieldTypeDate:
+		if text, isString := value.(string); isString {
+			date, err := time.Parse(taskDateLayout, text)
+			if err != nil {
+				return value, fmt.Errorf("%w: %s %q", ErrTaskFieldTypeMismatch, fieldType, text)
+			}
+			return date, err
+		}
+	case FieldTypeUser:
+		switch value := value.(type) {
+		case float64:
+			return UserRef{Id: int(value)}, err
+		case map[string]interface{}:
+			id, isNumber := value["id"].(float64)
+			if isNumber {
+				name, _ := value["name"].(string)
+				return UserRef{Id: int(id), Name: name}, err
+			}
+		}
+	case FieldTypeTaskLink:
+		switch value := value.(type) {
+		case float64:
+			return int(value), err
+		case map[string]interface{}:
+			if id, isNumber := value["id"].(float64); isNumber {
+				return int(id), err
+			}
+		}
+	default:
+		return value, err
+	}
+
+	return value, fmt.Errorf("%w: %s %T", ErrTaskFieldTypeMismatch, fieldType, value)
+}
+
+// toFloatNumbers replaces the json numbers by float64, in the objects and arrays too, as json.Unmarshal decodes them
+func toFloatNumbers(value interface{}) interface{} {
+	switch value := value.(type) {
+	case json.Number:
+		number, _ := value.Float64()
+		return number
+	case map[string]interface{}:
+		for key, item := range value {
+			value[key] = toFloatNumbers(item)
+		}
+	case []interface{}:
+		for i, item := range value {
+			value[i] = toFloatNumbers(item)
+		}
+	}
+
+	return value
+}
+
+// encodeFieldValue converts the decoded values back to the api format, the other values are sent as is, a currency
+// value is sent as the {"value": 1500.5, "currency": "RUB"} object
+func encodeFieldValue(value interface{}) interface{} {
+	switch value := value.(type) {
+	case *CurrencyFieldValue:
+		if value != nil {
+			return *value
+		}
+		return nil
+	case time.Time:
+		return value.Format(taskDateLayout)
+	case UserRef:
+		return value.Id
+	}
+
+	return value
+}
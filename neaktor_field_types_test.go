@@ -0,0 +1,58 @@
+package neaktor_api
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestNeaktorApiFieldTypes(t *testing.T) {
+	for _, item := range []struct {
+		fieldType FieldType
+		value     interface{}
+		expected  interface{}
+	}{
+		{FieldTypeText, "", ""},
+		{FieldTypeNumber, json.Number("0.1"), MustParseDecimal("0.1")},
+		{FieldTypeNumber, "1.5", MustParseDecimal("1.5")},
+		{FieldTypeUnknown, json.Number("1.5"), 1.5},
+		{FieldTypeNumber, "", nil},
+		{FieldTypeDate, "01-03-2024T09:30:00", time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)},
+		{FieldTypeUser, json.Number("7"), UserRef{Id: 7}},
+		{FieldTypeUser, map[string]interface{}{"id": float64(7), "name": "Менеджер"}, UserRef{Id: 7, Name: "Менеджер"}},
+		{FieldTypeTaskLink, float64(101), 101},
+		{FieldTypeTaskLink, map[string]interface{}{"id": float64(101)}, 101},
+		{FieldTypeCurrency, map[string]interface{}{"value": json.Number("1500.10"), "currency": "RUB"}, CurrencyFieldValue{Value: MustParseDecimal("1500.10"), Currency: "RUB"}},
+		{FieldTypeSelect, "o1", "o1"},
+		{FieldTypeUnknown, "01-03-2024T09:30:00", "01-03-2024T09:30:00"},
+		{FieldTypeDate, nil, nil},
+	} {
+		value, err := decodeFieldValue(item.fieldType, item.value)
+		if err != nil {
+			t.Fatalf("%s %v decode error: %v", item.fieldType, item.value, err)
+		}
+		if value != item.expected {
+			t.Errorf("expected %s %v decoded as %#v, got %#v", item.fieldType, item.value, item.expected, value)
+		}
+	}
+
+	for fieldType, value := range map[FieldType]interface{}{
+		FieldTypeNumber:   "много",
+		FieldTypeDate:     "2024-03-01",
+		FieldTypeUser:     "Менеджер",
+		FieldTypeTaskLink: true,
+		FieldTypeCurrency: map[string]interface{}{"currency": "RUB"},
+	} {
+		if _, err := decodeFieldValue(fieldType, value); !errors.Is(err, ErrTaskFieldTypeMismatch) {
+			t.Errorf("expected ErrTaskFieldTypeMismatch for %s %v, got %v", fieldType, value, err)
+		}
+	}
+
+	if value := encodeFieldValue(time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)); value != "01-03-2024T09:30:00" {
+		t.Errorf("unexpected encoded date %v", value)
+	}
+	if value := encodeFieldValue(UserRef{Id: 7, Name: "Менеджер"}); value != 7 {
+		t.Errorf("unexpected encoded user %v", value)
+	}
+}
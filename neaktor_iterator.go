@@ -0,0 +1,432 @@
+package neaktor_api
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+
+	nethttp "net/http"
+	neturl "net/url"
+)
+
+// tasksPageSize is the default page size of the task list requests
+const tasksPageSize = 50
+
+// ITaskIterator fetches the task pages on demand, only one page is held in memory, see WithPageConcurrency:
+//
+//	iterator := model.IterateTasksByStatus(status)
+//	for iterator.Next() {
+//		task := iterator.Task()
+//	}
+//	if err := iterator.Err(); err != nil {
+//		...
+//	}
+//
+// Breaking the loop early skips the remaining pages, there is nothing to close.
+type ITaskIterator interface {
+	// Next advances to the next task, fetching the next page when needed, it returns false when the tasks are
+	// exhausted or a request failed
+	Next() bool
+	// Task returns the current task, it is valid after Next returned true
+	Task() ITask
+	// Err returns the error which stopped the iteration, it is nil when the tasks are exhausted
+	Err() error
+}
+
+// WithPageConcurrency fetches up to concurrency task pages at once, after the first page tells the total. The pages
+// still go through the api limiter and come back in order, an iterator holds up to concurrency pages in memory.
+// The default is 1, the pages are fetched one after another.
+func WithPageConcurrency(concurrency int) NeaktorOption {
+	return func(n *Neaktor) {
+		n.pageConcurrency = max(1, concurrency)
+	}
+}
+
+// ScanOptions makes an iterator consistent while the tasks move between statuses, e.g. the workers processing the
+// scanned status move its tasks out of it and the next pages shift back
+type ScanOptions struct {
+	// MaxRecheckPasses is the number of passes over the pages again, made while the previous pass saw the total
+	// changing or the tasks shifting, only the tasks not seen before are returned by a re-check pass
+	MaxRecheckPasses int
+	// FollowNextLinks requests the links.next urls of the responses instead of counting the pages by the total,
+	// the pages are fetched one after another then
+	FollowNextLinks bool
+}
+
+var DefaultScanOptions = ScanOptions{
+	MaxRecheckPasses: 2,
+}
+
+// ITaskScan is a task iterator which returns every task at most once
+type ITaskScan interface {
+	ITaskIterator
+	// MayBeIncomplete reports whether the tasks were still moving during the last re-check pass, so some of them may
+	// have been missed, it is valid after Next returned false
+	MayBeIncomplete() bool
+}
+
+type TaskIterator struct {
+	ctx      context.Context
+	model    *Model
+	query    *TaskQuery
+	queries  []neturl.Values // the query params of each status, set on the first Next
+	pageSize int
+	limit    int
+	count    int // tasks returned by Next
+
+	page     int
+	maxPages int
+	isLoaded bool // the last page of the current query is loaded
+	tasks    []ITask
+	task     ITask
+	err      error
+
+	// consistent scans only
+	scanOptions     *ScanOptions
+	seenTaskIds     map[int]bool
+	pass            int
+	passTotal       int    // the total of the first page of the pass
+	nextUrl         string // links.next of the last page
+	isChanged       bool   // the pass saw the total changing, the tasks shifting or new tasks on a re-check
+	mayBeIncomplete bool
+}
+
+type taskPage struct {
+	tasks   []ITask
+	total   int
+	nextUrl string
+}
+
+// newTaskIterator iterates the tasks of every status of the query in order, each status is paged separately
+func (m *Model) newTaskIterator(ctx context.Context, query *TaskQuery) *TaskIterator {
+	return &TaskIterator{
+		ctx:      ctx,
+		model:    m,
+		query:    query,
+		pageSize: query.GetPageSize(),
+		limit:    query.GetLimit(),
+	}
+}
+
+// newTaskScan is the iterator which deduplicates the tasks by id and re-checks the pages for the missed ones
+func (m *Model) newTaskScan(ctx context.Context, query *TaskQuery, scanOptions ScanOptions) *TaskIterator {
+	iterator := m.newTaskIterator(ctx, query)
+	iterator.scanOptions = &scanOptions
+	iterator.seenTaskIds = make(map[int]bool, 0)
+
+	return iterator
+}
+
+func (i *TaskIterator) Next() bool {
+	if i.limit > 0 && i.count >= i.limit {
+		return false
+	}
+
+	if i.query != nil && i.queries == nil && i.err == nil {
+		i.queries, i.err = i.model.queryValues(i.ctx, i.query)
+	}
+
+	// a sorted query holds the tasks until the last page of the status is loaded
+	for len(i.tasks) <= 0 || i.isSorted() && !i.isLoaded {
+		if i.err != nil || len(i.queries) <= 0 {
+			return false
+		}
+
+		if i.isLoaded {
+			if !i.recheck() {
+				i.queries = i.queries[1:]
+				i.pass = 0
+			}
+
+			i.page = 0
+			i.maxPages = 0
+			i.nextUrl = ""
+			i.isChanged = false
+			i.isLoaded = false
+			continue
+		}
+
+		i.err = i.fetch()
+		if i.err == nil && i.isLoaded && i.isSorted() {
+			sortTasks(i.tasks, i.query.GetSorts())
+		}
+	}
+
+	i.task = i.tasks[0]
+	i.tasks = i.tasks[1:]
+	i.count++
+
+	return true
+}
+
+func (i *TaskIterator) Task() ITask {
+	return i.task
+}
+
+func (i *TaskIterator) Err() error {
+	return i.err
+}
+
+func (i *TaskIterator) MayBeIncomplete() bool {
+	return i.mayBeIncomplete
+}
+
+func (i *TaskIterator) isSorted() bool {
+	return i.query != nil && len(i.query.GetSorts()) > 0
+}
+
+// isMatched applies the range filters the api doesn't
+func (i *TaskIterator) isMatched(task ITask) bool {
+	return i.query == nil || i.query.isMatched(task)
+}
+
+// recheck decides whether the loaded query needs another pass
+func (i *TaskIterator) recheck() bool {
+	if i.scanOptions == nil || !i.isChanged {
+		return false
+	}
+
+	if i.pass >= i.scanOptions.MaxRecheckPasses {
+		i.mayBeIncomplete = true
+		return false
+	}
+
+	i.pass++
+	i.model.neaktor.log.Debugf("tasks moved during the scan pass, re-checking (pass: %d)", i.pass)
+
+	return true
+}
+
+// fetch loads the first page alone, the next ones by up to pageConcurrency pages at once
+func (i *TaskIterator) fetch() (err error) {
+	pages := 1
+	if i.page > 0 && (i.scanOptions == nil || !i.scanOptions.FollowNextLinks) {
+		pages = min(i.model.neaktor.pageConcurrency, i.maxPages-i.page)
+	}
+
+	if pages <= 1 {
+		page, err := i.fetchPage(i.ctx, i.page)
+		if err != nil {
+			return err
+		}
+
+		i.addPage(page)
+		i.maxPages = int(math.Ceil(float64(page.total) / float64(i.pageSize)))
+		i.nextUrl = page.nextUrl
+		i.page++
+
+		if i.scanOptions != nil && i.scanOptions.FollowNextLinks {
+			i.isLoaded = len(i.nextUrl) <= 0 || len(page.tasks) <= 0
+		} else {
+			i.isLoaded = i.page >= i.maxPages || len(page.tasks) <= 0
+		}
+
+		return err
+	}
+
+	pagesInOrder, err := i.fetchPages(i.page, pages)
+	if err != nil {
+		return err
+	}
+
+	for _, page := range pagesInOrder {
+		i.addPage(page)
+	}
+	i.page += pages
+	i.isLoaded = i.page >= i.maxPages
+
+	return err
+}
+
+// addPage queues the page tasks, a scan skips the tasks seen before and watches the tasks moving
+func (i *TaskIterator) addPage(page taskPage) {
+	if i.scanOptions == nil {
+		for _, task := range page.tasks {
+			if i.isMatched(task) {
+				i.tasks = append(i.tasks, task)
+			}
+		}
+		return
+	}
+
+	if i.page == 0 {
+		i.passTotal = page.total
+	} else if page.total != i.passTotal {
+		i.isChanged = true
+	}
+
+	for _, task := range page.tasks {
+		if i.seenTaskIds[task.GetId()] {
+			// a task seen again in the first pass was shifted from the previous page
+			if i.pass == 0 {
+				i.isChanged = true
+			}
+			continue
+		}
+
+		// a task missed by the previous pass
+		if i.pass > 0 {
+			i.isChanged = true
+		}
+
+		i.seenTaskIds[task.GetId()] = true
+		if i.isMatched(task) {
+			i.tasks = append(i.tasks, task)
+		}
+	}
+}
+
+// fetchPages loads the pages in parallel and returns them in order, the first error cancels the other requests
+func (i *TaskIterator) fetchPages(firstPage int, pages int) (pagesInOrder []taskPage, err error) {
+	ctx, cancel := context.WithCancel(i.ctx)
+	defer cancel()
+
+	pagesInOrder = make([]taskPage, pages)
+
+	errOnce := sync.Once{}
+	wg := sync.WaitGroup{}
+	for j := 0; j < pages; j++ {
+		j := j
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			page, pageErr := i.fetchPage(ctx, firstPage+j)
+			if pageErr != nil {
+				errOnce.Do(func() {
+					err = pageErr
+					cancel()
+				})
+				return
+			}
+
+			pagesInOrder[j] = page
+		}()
+	}
+	wg.Wait()
+
+	return pagesInOrder, err
+}
+
+func (i *TaskIterator) fetchPage(ctx context.Context, pageNumber int) (page taskPage, err error) {
+	var request *HttpRequest
+	if pageNumber > 0 && len(i.nextUrl) > 0 && i.scanOptions != nil && i.scanOptions.FollowNextLinks {
+		// the link is resolved against the gateway directory, in case it is relative
+		nextUrl := mustParseUrl(strings.TrimSuffix(i.model.neaktor.apiGateway, "/") + "/").ResolveReference(mustParseUrl(i.nextUrl))
+		request = i.model.neaktor.newHttpRequest(nethttp.MethodGet, "/v1/tasks", nextUrl.String())
+	} else {
+		request = i.model.neaktor.newHttpRequest(nethttp.MethodGet, "/v1/tasks", mustUrlJoinPath(i.model.neaktor.apiGateway, "tasks"))
+
+		for key, values := range i.queries[0] {
+			for _, value := range values {
+				request.Params.Add(key, value)
+			}
+		}
+
+		request.Params.Add("model_id", i.model.id)
+		request.Params.Add("size", strconv.Itoa(i.pageSize))
+		request.Params.Add("page", strconv.Itoa(pageNumber))
+	}
+
+	var tasksResponse tasksResponse
+	if err := i.model.neaktor.call(ctx, request, &tasksResponse); err != nil {
+		return page, err
+	}
+
+	for _, taskData := range tasksResponse.Data {
+		task, err := i.model.newTaskFromResponse(taskData)
+		if err != nil {
+			return page, err
+		}
+
+		page.tasks = append(page.tasks, task)
+	}
+
+	page.total = tasksResponse.Total
+	page.nextUrl = tasksResponse.Links.Next
+
+	return page, err
+}
+
+//
+
+func (m *Model) IterateTasks(query *TaskQuery) ITaskIterator {
+	return m.IterateTasksWithContext(context.Background(), query)
+}
+
+func (m *Model) IterateTasksWithContext(ctx context.Context, query *TaskQuery) ITaskIterator {
+	return m.newTaskIterator(ctx, query)
+}
+
+func (m *Model) IterateTasksByStatus(status ModelStatus) ITaskIterator {
+	return m.IterateTasksByStatusWithContext(context.Background(), status)
+}
+
+func (m *Model) IterateTasksByStatusWithContext(ctx context.Context, status ModelStatus) ITaskIterator {
+	return m.newTaskIterator(ctx, m.Query().Statuses(status))
+}
+
+func (m *Model) IterateTasksByStatuses(statuses []ModelStatus) ITaskIterator {
+	return m.IterateTasksByStatusesWithContext(context.Background(), statuses)
+}
+
+func (m *Model) IterateTasksByStatusesWithContext(ctx context.Context, statuses []ModelStatus) ITaskIterator {
+	// no statuses are no tasks here, unlike a query without statuses
+	if len(statuses) <= 0 {
+		return &TaskIterator{}
+	}
+
+	return m.newTaskIterator(ctx, m.Query().Statuses(statuses...))
+}
+
+func (m *Model) IterateTasksByStatusAndFields(status ModelStatus, fields []TaskField) ITaskIterator {
+	return m.IterateTasksByStatusAndFieldsWithContext(context.Background(), status, fields)
+}
+
+func (m *Model) IterateTasksByStatusAndFieldsWithContext(ctx context.Context, status ModelStatus, fields []TaskField) ITaskIterator {
+	return m.newTaskIterator(ctx, m.Query().Statuses(status).WhereFields(fields))
+}
+
+func (m *Model) IterateTasksByFields(fields []TaskField) ITaskIterator {
+	return m.IterateTasksByFieldsWithContext(context.Background(), fields)
+}
+
+func (m *Model) IterateTasksByFieldsWithContext(ctx context.Context, fields []TaskField) ITaskIterator {
+	return m.newTaskIterator(ctx, m.Query().WhereFields(fields))
+}
+
+func (m *Model) ScanTasks(query *TaskQuery, scanOptions ScanOptions) ITaskScan {
+	return m.ScanTasksWithContext(context.Background(), query, scanOptions)
+}
+
+func (m *Model) ScanTasksWithContext(ctx context.Context, query *TaskQuery, scanOptions ScanOptions) ITaskScan {
+	return m.newTaskScan(ctx, query, scanOptions)
+}
+
+func (m *Model) ScanTasksByStatus(status ModelStatus, scanOptions ScanOptions) ITaskScan {
+	return m.ScanTasksByStatusWithContext(context.Background(), status, scanOptions)
+}
+
+func (m *Model) ScanTasksByStatusWithContext(ctx context.Context, status ModelStatus, scanOptions ScanOptions) ITaskScan {
+	return m.newTaskScan(ctx, m.Query().Statuses(status), scanOptions)
+}
+
+func (m *Model) ScanTasksByStatusAndFields(status ModelStatus, fields []TaskField, scanOptions ScanOptions) ITaskScan {
+	return m.ScanTasksByStatusAndFieldsWithContext(context.Background(), status, fields, scanOptions)
+}
+
+func (m *Model) ScanTasksByStatusAndFieldsWithContext(ctx context.Context, status ModelStatus, fields []TaskField, scanOptions ScanOptions) ITaskScan {
+	return m.newTaskScan(ctx, m.Query().Statuses(status).WhereFields(fields), scanOptions)
+}
+
+// collectTasks drains the iterator, the tasks fetched before an error are returned as well
+func collectTasks(iterator ITaskIterator) (tasks []ITask, err error) {
+	for iterator.Next() {
+		tasks = append(tasks, iterator.Task())
+	}
+
+	return tasks, iterator.Err()
+}
@@ -0,0 +1,284 @@
+package neaktor_api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+
+	requrl "github.com/wangluozhe/requests/url"
+)
+
+func TestNeaktorApiIterator(t *testing.T) {
+	failedPage := -1
+
+	// newPagedModel serves total tasks in the "новый заказ" status, page by page
+	newPagedModel := func(total int, pages *[]string, options ...NeaktorOption) *Model {
+		pagesLock := sync.Mutex{}
+
+		httpDoer := HttpDoerFunc(func(ctx context.Context, request *HttpRequest) (*HttpResponse, error) {
+			page, _ := strconv.Atoi(request.Params.Get("page"))
+			size, _ := strconv.Atoi(request.Params.Get("size"))
+
+			pagesLock.Lock()
+			*pages = append(*pages, request.Params.Get("page"))
+			pagesLock.Unlock()
+
+			if page == failedPage {
+				return &HttpResponse{StatusCode: http.StatusInternalServerError, Body: []byte(`{"code":"500","message":"failed"}`)}, nil
+			}
+
+			data := make([]map[string]interface{}, 0)
+			for id := page*size + 1; id <= min(total, (page+1)*size); id++ {
+				data = append(data, map[string]interface{}{"id": id, "status": "новый заказ", "fields": []interface{}{}})
+			}
+
+			body, _ := json.Marshal(map[string]interface{}{"data": data, "page": page, "size": size, "total": total})
+
+			return &HttpResponse{StatusCode: http.StatusOK, Body: body}, nil
+		})
+
+		neaktor := NewNeaktor(*requrl.NewRequest(), "t1o2k3e4n5", 6000, append([]NeaktorOption{WithHttpDoer(httpDoer)}, options...)...)
+
+		return NewModel(neaktor.(*Neaktor), "m1", map[string]ModelStatus{
+			"s_new": {Id: "s_new", Name: "новый заказ"},
+		}, map[string]ModelField{}).(*Model)
+	}
+
+	t.Run("Lazy", func(t *testing.T) {
+		var pages []string
+		model := newPagedModel(120, &pages)
+
+		iterator := model.IterateTasksByStatus(model.MustGetStatus("новый заказ"))
+		for i := 0; i < tasksPageSize; i++ {
+			if !iterator.Next() {
+				t.Fatalf("unexpected end at %d: %v", i, iterator.Err())
+			}
+		}
+		if len(pages) != 1 {
+			t.Fatalf("expected only the first page, got %v", pages)
+		}
+
+		if !iterator.Next() || iterator.Task().GetId() != tasksPageSize+1 {
+			t.Fatalf("expected the first task of the second page")
+		}
+		if len(pages) != 2 {
+			t.Fatalf("expected two pages, got %v", pages)
+		}
+	})
+
+	t.Run("Pages", func(t *testing.T) {
+		for _, total := range []int{0, 49, 50, 100, 120} {
+			var pages []string
+			model := newPagedModel(total, &pages)
+
+			tasks, err := model.GetTasksByStatus(model.MustGetStatus("новый заказ"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(tasks) != total {
+				t.Fatalf("expected %d tasks, got %d", total, len(tasks))
+			}
+			if expected := max(1, (total+tasksPageSize-1)/tasksPageSize); len(pages) != expected {
+				t.Fatalf("expected %d pages for %d tasks, got %v", expected, total, pages)
+			}
+			if total > 0 && tasks[0].GetStatus().Id != "s_new" {
+				t.Fatalf("unexpected status %+v", tasks[0].GetStatus())
+			}
+		}
+	})
+
+	t.Run("Concurrency", func(t *testing.T) {
+		var pages []string
+		model := newPagedModel(420, &pages, WithPageConcurrency(4))
+
+		tasks, err := model.GetTasksByStatus(model.MustGetStatus("новый заказ"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, task := range tasks {
+			if task.GetId() != i+1 {
+				t.Fatalf("expected the task %d at %d, got %d", i+1, i, task.GetId())
+			}
+		}
+		if len(tasks) != 420 || len(pages) != 9 {
+			t.Fatalf("expected 420 tasks in 9 pages, got %d tasks in %v", len(tasks), pages)
+		}
+	})
+
+	t.Run("ConcurrencyError", func(t *testing.T) {
+		failedPage = 2
+		defer func() { failedPage = -1 }()
+
+		var pages []string
+		model := newPagedModel(420, &pages, WithPageConcurrency(4))
+
+		_, err := model.GetTasksByStatus(model.MustGetStatus("новый заказ"))
+		if !errors.Is(err, ErrCode500) {
+			t.Fatalf("expected ErrCode500, got %v", err)
+		}
+		if len(pages) > 5 {
+			t.Fatalf("expected no pages after the failed batch, got %v", pages)
+		}
+	})
+
+	t.Run("Limit", func(t *testing.T) {
+		var pages []string
+		model := newPagedModel(120, &pages)
+
+		tasks, err := model.Query().Statuses(model.MustGetStatus("новый заказ")).PageSize(20).Limit(30).Get()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(tasks) != 30 || len(pages) != 2 {
+			t.Fatalf("expected 30 tasks in 2 pages, got %d in %v", len(tasks), pages)
+		}
+	})
+
+	t.Run("Count", func(t *testing.T) {
+		var pages []string
+		model := newPagedModel(120, &pages)
+
+		count, err := model.CountTasksByStatus(model.MustGetStatus("новый заказ"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if count != 120 || len(pages) != 1 {
+			t.Fatalf("expected 120 tasks by a single request, got %d by %d", count, len(pages))
+		}
+
+		isExists, err := model.IsTasksByStatusesExists([]ModelStatus{model.MustGetStatus("новый заказ"), model.MustGetStatus("новый заказ")})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !isExists || len(pages) != 2 {
+			t.Fatalf("expected the tasks to exist by a single request, got %t by %d", isExists, len(pages)-1)
+		}
+
+		model = newPagedModel(0, &pages)
+		if isExists, err := model.IsTasksByStatusExists(model.MustGetStatus("новый заказ")); err != nil || isExists {
+			t.Fatalf("expected no tasks, got %t, %v", isExists, err)
+		}
+	})
+
+	t.Run("Context", func(t *testing.T) {
+		var pages []string
+		model := newPagedModel(120, &pages)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		iterator := model.IterateTasksByStatusWithContext(ctx, model.MustGetStatus("новый заказ"))
+		for i := 0; i < tasksPageSize; i++ {
+			iterator.Next()
+		}
+		cancel()
+
+		if iterator.Next() {
+			t.Fatal("expected the iteration to stop")
+		}
+		if iterator.Err() == nil {
+			t.Fatal("expected the context error")
+		}
+	})
+
+	t.Run("Scan", func(t *testing.T) {
+		// the tasks leave the status as soon as they are processed, so the next pages shift back
+		newMovingModel := func(total int, requestUrls *[]string) (*Model, func(id int)) {
+			inStatus := make(map[int]bool, 0)
+			for id := 1; id <= total; id++ {
+				inStatus[id] = true
+			}
+
+			httpDoer := HttpDoerFunc(func(ctx context.Context, request *HttpRequest) (*HttpResponse, error) {
+				url := mustParseUrl(request.Url)
+				query := url.Query()
+				for key, values := range request.Params {
+					query[key] = values
+				}
+				*requestUrls = append(*requestUrls, url.Path+"?"+query.Encode())
+
+				page, _ := strconv.Atoi(query.Get("page"))
+
+				ids := make([]int, 0)
+				for id := 1; id <= total; id++ {
+					if inStatus[id] {
+						ids = append(ids, id)
+					}
+				}
+
+				data := make([]map[string]interface{}, 0)
+				for _, id := range ids[min(len(ids), page*tasksPageSize):min(len(ids), (page+1)*tasksPageSize)] {
+					data = append(data, map[string]interface{}{"id": id, "status": "новый заказ", "fields": []interface{}{}})
+				}
+
+				links := map[string]string{}
+				if (page+1)*tasksPageSize < len(ids) {
+					query.Set("page", strconv.Itoa(page+1))
+					links["next"] = "tasks?" + query.Encode()
+				}
+
+				body, _ := json.Marshal(map[string]interface{}{"data": data, "links": links, "total": len(ids)})
+
+				return &HttpResponse{StatusCode: http.StatusOK, Body: body}, nil
+			})
+
+			neaktor := NewNeaktor(*requrl.NewRequest(), "t1o2k3e4n5", 6000, WithHttpDoer(httpDoer))
+			model := NewModel(neaktor.(*Neaktor), "m1", map[string]ModelStatus{
+				"s_new": {Id: "s_new", Name: "новый заказ"},
+			}, map[string]ModelField{}).(*Model)
+
+			return model, func(id int) { delete(inStatus, id) }
+		}
+
+		process := func(iterator ITaskIterator, moveTask func(id int)) (processed map[int]int) {
+			processed = make(map[int]int, 0)
+			for iterator.Next() {
+				processed[iterator.Task().GetId()]++
+				moveTask(iterator.Task().GetId())
+			}
+			if err := iterator.Err(); err != nil {
+				t.Fatal(err)
+			}
+
+			return processed
+		}
+
+		var requestUrls []string
+
+		model, moveTask := newMovingModel(120, &requestUrls)
+		if processed := process(model.IterateTasksByStatus(model.MustGetStatus("новый заказ")), moveTask); len(processed) == 120 {
+			t.Fatal("expected the plain iterator to skip the shifted tasks")
+		}
+
+		model, moveTask = newMovingModel(120, &requestUrls)
+		scan := model.ScanTasksByStatus(model.MustGetStatus("новый заказ"), DefaultScanOptions)
+		processed := process(scan, moveTask)
+		if len(processed) != 120 || scan.MayBeIncomplete() {
+			t.Fatalf("expected all 120 tasks once, got %d, incomplete: %t", len(processed), scan.MayBeIncomplete())
+		}
+		for id, count := range processed {
+			if count != 1 {
+				t.Fatalf("task %d processed %d times", id, count)
+			}
+		}
+
+		model, moveTask = newMovingModel(120, &requestUrls)
+		scan = model.ScanTasksByStatus(model.MustGetStatus("новый заказ"), ScanOptions{MaxRecheckPasses: 1})
+		process(scan, moveTask)
+		if !scan.MayBeIncomplete() {
+			t.Fatal("expected the scan to report it may be incomplete")
+		}
+
+		requestUrls = nil
+		model, moveTask = newMovingModel(120, &requestUrls)
+		scan = model.ScanTasksByStatus(model.MustGetStatus("новый заказ"), ScanOptions{MaxRecheckPasses: 2, FollowNextLinks: true})
+		if processed := process(scan, moveTask); len(processed) != 120 {
+			t.Fatalf("expected all 120 tasks, got %d", len(processed))
+		}
+		if len(requestUrls) < 2 || requestUrls[1] != "/v1/tasks?model_id=m1&page=1&size=50&status_id=s_new" {
+			t.Fatalf("expected the next link to be followed, got %v", requestUrls)
+		}
+	})
+}
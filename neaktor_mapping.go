@@ -0,0 +1,298 @@
+package neaktor_api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The struct fields are bound to the model fields by the neaktor tag with the field title:
+//
+//	type Order struct {
+//		Email    string             `neaktor:"email"`
+//		Delivery string             `neaktor:"доставка,omitempty"` // the option value of a select field
+//		Total    CurrencyFieldValue `neaktor:"итого,optional"`
+//		PaidAt   *time.Time         `neaktor:"оплачен,omitempty,optional"`
+//		Comment  string             // not bound without a tag
+//	}
+//
+// omitempty skips the zero values in Marshal, optional allows the task to miss the field in Unmarshal. A struct field
+// may be a string, a number, a bool, time.Time, Decimal, CurrencyFieldValue, UserRef, []string, interface{} or a
+// pointer to any of them, a nil pointer is an empty value.
+const mappingTag = "neaktor"
+
+var ErrMappingTarget = errors.New("MAPPING_TARGET_INVALID")
+
+type mappingField struct {
+	index       []int
+	title       string
+	isOmitEmpty bool
+	isOptional  bool
+}
+
+func Unmarshal(model IModel, task ITask, target interface{}) (err error) {
+	return UnmarshalWithContext(context.Background(), model, task, target)
+}
+
+// UnmarshalWithContext populates the struct pointed by target from the task fields, the select fields are read as the
+// option values, the options not cached at the read are requested with the context. Every tag must name a model field
+// and every not optional field must be present in the task, all the unknown and missing fields are reported at once.
+func UnmarshalWithContext(ctx context.Context, model IModel, task ITask, target interface{}) (err error) {
+	targetValue := reflect.ValueOf(target)
+	if targetValue.Kind() != reflect.Pointer || targetValue.IsNil() || targetValue.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("%w: %T is not a struct pointer", ErrMappingTarget, target)
+	}
+	structValue := targetValue.Elem()
+
+	mappingFields, err := parseMappingFields(structValue.Type())
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, mappingField := range mappingFields {
+		modelField, err := model.GetField(mappingField.title)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%w: %s", ErrModelFieldNotFound, mappingField.title))
+			continue
+		}
+
+		taskField, err := task.GetField(modelField)
+		if err != nil {
+			if !mappingField.isOptional {
+				errs = append(errs, fmt.Errorf("%w: %s", ErrTaskFieldNotFound, mappingField.title))
+			}
+			continue
+		}
+
+		if err := setStructField(ctx, structValue.FieldByIndex(mappingField.index), taskField); err != nil {
+			errs = append(errs, fmt.Errorf("field %s unmarshaling error: %w", mappingField.title, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func Marshal(model IModel, source interface{}) (fields []TaskField, err error) {
+	return MarshalWithContext(context.Background(), model, source)
+}
+
+// MarshalWithContext builds the task fields of the struct for CreateTask and UpdateFields, the option values of the
+// select fields are resolved to the option ids. Every tag must name a model field, all the unknown fields and the
+// unknown options are reported at once.
+func MarshalWithContext(ctx context.Context, model IModel, source interface{}) (fields []TaskField, err error) {
+	structValue := reflect.Indirect(reflect.ValueOf(source))
+	if structValue.Kind() != reflect.Struct {
+		return fields, fmt.Errorf("%w: %T is not a struct", ErrMappingTarget, source)
+	}
+
+	mappingFields, err := parseMappingFields(structValue.Type())
+	if err != nil {
+		return fields, err
+	}
+
+	var errs []error
+	for _, mappingField := range mappingFields {
+		modelField, err := model.GetField(mappingField.title)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%w: %s", ErrModelFieldNotFound, mappingField.title))
+			continue
+		}
+
+		value := structValue.FieldByIndex(mappingField.index)
+		if mappingField.isOmitEmpty && value.IsZero() {
+			continue
+		}
+
+		fieldValue, err := marshalFieldValue(ctx, model, modelField, value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("field %s marshaling error: %w", mappingField.title, err))
+			continue
+		}
+
+		fields = append(fields, TaskField{ModelField: modelField, Value: fieldValue})
+	}
+
+	return fields, errors.Join(errs...)
+}
+
+func MustMarshal(model IModel, source interface{}) (fields []TaskField) {
+	var err error
+	fields, err = Marshal(model, source)
+	if err != nil {
+		panic(err)
+	}
+
+	return fields
+}
+
+func parseMappingFields(structType reflect.Type) (mappingFields []mappingField, err error) {
+	for _, structField := range reflect.VisibleFields(structType) {
+		tag, isTagged := structField.Tag.Lookup(mappingTag)
+		if !isTagged || tag == "-" || structField.Anonymous {
+			continue
+		}
+		if !structField.IsExported() {
+			return mappingFields, fmt.Errorf("%w: %s is not exported", ErrMappingTarget, structField.Name)
+		}
+
+		title, options, _ := strings.Cut(tag, ",")
+		mappingField := mappingField{index: structField.Index, title: title}
+		for _, option := range strings.Split(options, ",") {
+			switch option {
+			case "omitempty":
+				mappingField.isOmitEmpty = true
+			case "optional":
+				mappingField.isOptional = true
+			}
+		}
+
+		mappingFields = append(mappingFields, mappingField)
+	}
+
+	return mappingFields, err
+}
+
+// setStructField converts the task field value with the TaskField accessors, an empty value sets the zero value. A
+// string is the option value of a select field, or of a field without a type when its option is cached at the read.
+func setStructField(ctx context.Context, value reflect.Value, taskField TaskField) (err error) {
+	if taskField.Value == nil {
+		value.Set(reflect.Zero(value.Type()))
+		return err
+	}
+
+	if value.Kind() == reflect.Pointer {
+		pointer := reflect.New(value.Type().Elem())
+		if err := setStructField(ctx, pointer.Elem(), taskField); err != nil {
+			return err
+		}
+
+		value.Set(pointer)
+		return err
+	}
+
+	var fieldValue interface{}
+
+	switch value.Interface().(type) {
+	case time.Time:
+		fieldValue, err = taskField.Time()
+	case Decimal:
+		fieldValue, err = taskField.Decimal()
+	case CurrencyFieldValue:
+		fieldValue, err = taskField.Currency()
+	case UserRef:
+		fieldValue, err = taskField.UserRef()
+	case []string:
+		fieldValue, err = taskField.Strings()
+	default:
+		switch value.Kind() {
+		case reflect.String:
+			if taskField.ModelField.Type == FieldTypeSelect {
+				fieldValue, err = taskField.OptionValueWithContext(ctx)
+			} else if len(taskField.Option) > 0 {
+				fieldValue = taskField.Option
+			} else {
+				fieldValue, err = taskField.String()
+			}
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			fieldValue, err = taskField.Int()
+		case reflect.Float32, reflect.Float64:
+			fieldValue, err = taskField.Float()
+		case reflect.Bool:
+			fieldValue, err = taskField.Bool()
+		case reflect.Interface:
+			fieldValue = taskField.Value
+		default:
+			return fmt.Errorf("%w: unsupported %s", ErrMappingTarget, value.Type())
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	convertedValue := reflect.ValueOf(fieldValue)
+	if !convertedValue.Type().ConvertibleTo(value.Type()) || isOverflow(value, convertedValue) {
+		return fmt.Errorf("%w: %T %v to %s", ErrTaskFieldTypeMismatch, fieldValue, fieldValue, value.Type())
+	}
+
+	value.Set(convertedValue.Convert(value.Type()))
+
+	return err
+}
+
+// isOverflow reports whether the number doesn't fit the number type of the struct field, a negative number never fits
+// an unsigned one
+func isOverflow(value reflect.Value, number reflect.Value) bool {
+	switch number.Kind() {
+	case reflect.Int:
+		switch value.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return value.OverflowInt(number.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			return number.Int() < 0 || value.OverflowUint(uint64(number.Int()))
+		}
+	case reflect.Float64:
+		if value.Kind() == reflect.Float32 {
+			return value.OverflowFloat(number.Float())
+		}
+	}
+
+	return false
+}
+
+// marshalFieldValue returns the struct field value as the task field value, the option value of a select field is
+// resolved to the option id, a float32 is taken by its shortest float32 text, 0.1 is "0.1"
+func marshalFieldValue(ctx context.Context, model IModel, modelField ModelField, value reflect.Value) (fieldValue interface{}, err error) {
+	if value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return fieldValue, err
+		}
+		value = value.Elem()
+	}
+
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if value.Int() < math.MinInt || value.Int() > math.MaxInt {
+			return fieldValue, fmt.Errorf("%w: %d overflows int", ErrTaskFieldTypeMismatch, value.Int())
+		}
+		return int(value.Int()), err
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if value.Uint() > math.MaxInt {
+			return fieldValue, fmt.Errorf("%w: %d overflows int", ErrTaskFieldTypeMismatch, value.Uint())
+		}
+		return int(value.Uint()), err
+	case reflect.Float32:
+		return ParseDecimal(strconv.FormatFloat(value.Float(), 'f', -1, 32))
+	case reflect.Float64:
+		return value.Float(), err
+	case reflect.Bool:
+		return value.Bool(), err
+	case reflect.String:
+		if modelField.Type != FieldTypeSelect {
+			return value.String(), err
+		}
+		return marshalOptionValue(ctx, model, modelField, value.String())
+	}
+
+	return value.Interface(), err
+}
+
+func marshalOptionValue(ctx context.Context, model IModel, modelField ModelField, text string) (fieldValue interface{}, err error) {
+	options, err := model.GetFieldOptionsWithContext(ctx, modelField)
+	if err != nil {
+		return fieldValue, err
+	}
+
+	for _, option := range options {
+		if option.GetValue() == text || option.GetId() == text {
+			return option.GetId(), err
+		}
+	}
+
+	return fieldValue, fmt.Errorf("%w: %q", ErrModelCustomFieldOptionNotFound, text)
+}
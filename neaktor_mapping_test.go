@@ -0,0 +1,184 @@
+package neaktor_api_test
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	requrl "github.com/wangluozhe/requests/url"
+
+	neaktor_api "github.com/tanreon/go-neaktor-api"
+	"github.com/tanreon/go-neaktor-api/neaktortest"
+)
+
+func TestNeaktorApiMapping(t *testing.T) {
+	type Order struct {
+		Email    string                          `neaktor:"email"`
+		Amount   float64                         `neaktor:"сумма"`
+		Delivery string                          `neaktor:"доставка"`
+		PaidAt   time.Time                       `neaktor:"оплачен"`
+		Manager  neaktor_api.UserRef             `neaktor:"менеджер"`
+		Total    neaktor_api.CurrencyFieldValue  `neaktor:"итого"`
+		Prepaid  *neaktor_api.CurrencyFieldValue `neaktor:"предоплата,omitempty,optional"`
+		Comment  string
+	}
+
+	server := neaktortest.NewServer()
+	t.Cleanup(server.Close)
+
+	server.AddModel(neaktortest.Model{
+		Id:   "m1",
+		Name: "Заказ",
+		Fields: []neaktortest.Field{
+			{Id: "f_email", Name: "email", State: "EDITABLE", Type: "TEXT"},
+			{Id: "f_amount", Name: "сумма", State: "EDITABLE", Type: "NUMBER"},
+			{Id: "f_delivery", Name: "доставка", State: "EDITABLE", Type: "SELECT"},
+			{Id: "f_paid", Name: "оплачен", State: "EDITABLE", Type: "DATE"},
+			{Id: "f_manager", Name: "менеджер", State: "EDITABLE", Type: "USER"},
+			{Id: "f_total", Name: "итого", State: "EDITABLE", Type: "CURRENCY"},
+			{Id: "f_prepaid", Name: "предоплата", State: "EDITABLE", Type: "CURRENCY"},
+		},
+		Statuses: []neaktortest.Status{{Id: "s_new", Name: "новый заказ", Type: "START"}},
+	})
+	server.AddCustomField(neaktortest.CustomField{
+		Id:      "f_delivery",
+		Name:    "доставка",
+		Type:    "SELECT",
+		Options: []neaktortest.Option{{Id: "o_courier", Value: "курьер"}, {Id: "o_pickup", Value: "самовывоз"}},
+	})
+	server.AddTask(neaktortest.Task{
+		ModelId:  "m1",
+		StatusId: "s_new",
+		Fields: map[string]interface{}{
+			"f_email":    "user@mail.ru",
+			"f_amount":   float64(2),
+			"f_delivery": "o_courier",
+			"f_paid":     "01-03-2024T09:30:00",
+			"f_manager":  map[string]interface{}{"id": 7, "name": "Менеджер"},
+			"f_total":    map[string]interface{}{"value": 1500.5, "currency": "RUB"},
+			"f_prepaid":  map[string]interface{}{"value": 500, "currency": "RUB"},
+		},
+	})
+	server.AddRouting("m1", "s_new", neaktortest.Assignee{Id: 7, Name: "Менеджер", Type: "USER"})
+
+	neaktor := neaktor_api.NewNeaktor(*requrl.NewRequest(), server.AccessToken(), 6000,
+		neaktor_api.WithBaseUrl(server.URL), neaktor_api.WithHttpDoer(neaktor_api.NewNetHttpDoer(server.Client())))
+
+	model := neaktor.MustGetModelByTitle("Заказ")
+	newStatus := model.MustGetStatus("новый заказ")
+	task := model.MustGetTaskById(1)
+
+	var order Order
+	canceledCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := neaktor_api.UnmarshalWithContext(canceledCtx, model, task, &order); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the context passed to the options request, got %v", err)
+	}
+
+	if err := neaktor_api.Unmarshal(model, task, &order); err != nil {
+		t.Fatal(err)
+	}
+	if order.Email != "user@mail.ru" || order.Delivery != "курьер" || order.Manager.Id != 7 || order.Total.Value.String() != "1500.5" || order.Prepaid == nil || order.PaidAt.Day() != 1 {
+		t.Fatalf("unexpected order %+v", order)
+	}
+
+	order = Order{
+		Email:    "new@mail.ru",
+		Amount:   3,
+		Delivery: "самовывоз",
+		PaidAt:   time.Date(2024, 4, 2, 10, 0, 0, 0, time.UTC),
+		Manager:  neaktor_api.UserRef{Id: 8},
+		Total:    neaktor_api.CurrencyFieldValue{Value: neaktor_api.MustParseDecimal("99.90"), Currency: "RUB"},
+	}
+	createdTask := model.MustCreateTask(model.MustGetAssignee(newStatus, "Менеджер"), neaktor_api.MustMarshal(model, order))
+
+	storedTask, _ := server.Task(createdTask.GetId())
+	if storedTask.Fields["f_delivery"] != "o_pickup" || storedTask.Fields["f_paid"] != "02-04-2024T10:00:00" || storedTask.Fields["f_manager"] != float64(8) {
+		t.Fatalf("unexpected stored task %v", storedTask.Fields)
+	}
+	if _, present := storedTask.Fields["f_prepaid"]; present {
+		t.Fatal("expected the empty prepaid to be omitted")
+	}
+
+	var createdOrder Order
+	if err := neaktor_api.Unmarshal(model, model.MustGetTaskById(createdTask.GetId()), &createdOrder); err != nil {
+		t.Fatal(err)
+	}
+	if createdOrder.Delivery != "самовывоз" || createdOrder.Amount != 3 || createdOrder.Total.Value.Cmp(order.Total.Value) != 0 || createdOrder.Prepaid != nil {
+		t.Fatalf("unexpected created order %+v", createdOrder)
+	}
+
+	for _, request := range server.Requests() {
+		if strings.HasPrefix(request.Path, "/v1/customfields/") && request.Path != "/v1/customfields/f_delivery" {
+			t.Fatalf("expected the options requested for the select field only, got %s", request.Path)
+		}
+	}
+
+	var invalidOrder struct {
+		Discount string                         `neaktor:"скидка"`
+		Prepaid  neaktor_api.CurrencyFieldValue `neaktor:"предоплата"`
+	}
+	err := neaktor_api.Unmarshal(model, createdTask, &invalidOrder)
+	if !errors.Is(err, neaktor_api.ErrModelFieldNotFound) || !errors.Is(err, neaktor_api.ErrTaskFieldNotFound) {
+		t.Fatalf("expected the unknown and the missing fields, got %v", err)
+	}
+
+	order.Delivery = "почта"
+	if _, err := neaktor_api.Marshal(model, order); !errors.Is(err, neaktor_api.ErrModelCustomFieldOptionNotFound) {
+		t.Fatalf("expected ErrModelCustomFieldOptionNotFound, got %v", err)
+	}
+}
+
+func TestNeaktorApiMappingNumbers(t *testing.T) {
+	countField := neaktor_api.ModelField{Id: "f_count", Name: "количество", Type: neaktor_api.FieldTypeNumber}
+	model := neaktor_api.NewModel(nil, "m1", map[string]neaktor_api.ModelStatus{}, map[string]neaktor_api.ModelField{countField.Id: countField}).(*neaktor_api.Model)
+
+	newTask := func(value interface{}) neaktor_api.ITask {
+		return neaktor_api.NewTask(model, neaktor_api.ModelStatus{}, 1, "T-1", time.Time{}, time.Time{}, time.Time{}, []neaktor_api.TaskField{{ModelField: countField, Value: value}})
+	}
+	var small struct {
+		Count int8 `neaktor:"количество"`
+	}
+	if err := neaktor_api.Unmarshal(model, newTask(neaktor_api.MustParseDecimal("100")), &small); err != nil || small.Count != 100 {
+		t.Fatalf("expected 100, got %d, %v", small.Count, err)
+	}
+	if err := neaktor_api.Unmarshal(model, newTask(neaktor_api.MustParseDecimal("300")), &small); !errors.Is(err, neaktor_api.ErrTaskFieldTypeMismatch) {
+		t.Fatalf("expected ErrTaskFieldTypeMismatch for 300 in int8, got %d, %v", small.Count, err)
+	}
+
+	var unsigned struct {
+		Count uint16 `neaktor:"количество"`
+	}
+	if err := neaktor_api.Unmarshal(model, newTask(neaktor_api.MustParseDecimal("-1")), &unsigned); !errors.Is(err, neaktor_api.ErrTaskFieldTypeMismatch) {
+		t.Fatalf("expected ErrTaskFieldTypeMismatch for -1 in uint16, got %d, %v", unsigned.Count, err)
+	}
+	if err := neaktor_api.Unmarshal(model, newTask(neaktor_api.MustParseDecimal("65536")), &unsigned); !errors.Is(err, neaktor_api.ErrTaskFieldTypeMismatch) {
+		t.Fatalf("expected ErrTaskFieldTypeMismatch for 65536 in uint16, got %d, %v", unsigned.Count, err)
+	}
+
+	var float struct {
+		Count float32 `neaktor:"количество"`
+	}
+	if err := neaktor_api.Unmarshal(model, newTask(neaktor_api.MustParseDecimal("1e300")), &float); !errors.Is(err, neaktor_api.ErrTaskFieldTypeMismatch) {
+		t.Fatalf("expected ErrTaskFieldTypeMismatch for 1e300 in float32, got %v, %v", float.Count, err)
+	}
+
+	if fields, err := neaktor_api.Marshal(model, struct {
+		Count uint64 `neaktor:"количество"`
+	}{Count: math.MaxUint64}); !errors.Is(err, neaktor_api.ErrTaskFieldTypeMismatch) {
+		t.Fatalf("expected ErrTaskFieldTypeMismatch for MaxUint64, got %v, %v", fields, err)
+	}
+	if fields, err := neaktor_api.Marshal(model, struct {
+		Count uint8 `neaktor:"количество"`
+	}{Count: 255}); err != nil || fields[0].Value != 255 {
+		t.Fatalf("expected 255, got %v, %v", fields, err)
+	}
+	if fields, err := neaktor_api.Marshal(model, struct {
+		Count float32 `neaktor:"количество"`
+	}{Count: 0.1}); err != nil || fields[0].Value != neaktor_api.MustParseDecimal("0.1") {
+		t.Fatalf("expected the float32 taken as 0.1, got %v, %v", fields, err)
+	}
+}
@@ -0,0 +1,242 @@
+package neaktor_api
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	nethttp "net/http"
+)
+
+const (
+	CacheModel       = "model"
+	CacheCustomField = "custom_field"
+	CacheAssignee    = "assignee"
+)
+
+// IMetrics receives the client measurements, it's called concurrently
+type IMetrics interface {
+	// ObserveRequest is called for every attempt, statusCode is 0 when no response was received
+	ObserveRequest(method, endpoint string, statusCode int, duration time.Duration)
+	// ObserveRateLimitWait is called with the time spent blocked in the api limiter
+	ObserveRateLimitWait(duration time.Duration)
+	// ObserveCache is called on every lookup of the model, custom field and assignee caches
+	ObserveCache(cache string, isHit bool)
+}
+
+func WithMetrics(metrics IMetrics) NeaktorOption {
+	return func(n *Neaktor) {
+		n.metrics = metrics
+	}
+}
+
+type nopMetrics struct{}
+
+func (nopMetrics) ObserveRequest(method, endpoint string, statusCode int, duration time.Duration) {}
+func (nopMetrics) ObserveRateLimitWait(duration time.Duration)                                    {}
+func (nopMetrics) ObserveCache(cache string, isHit bool)                                          {}
+
+func (n *Neaktor) metricsMiddleware(next RequestHandler) RequestHandler {
+	return func(ctx context.Context, request *HttpRequest) (response *HttpResponse, err error) {
+		startedAt := time.Now()
+
+		response, err = next(ctx, request)
+
+		statusCode := 0
+		if response != nil {
+			statusCode = response.StatusCode
+		}
+		n.metrics.ObserveRequest(request.Method, request.route(), statusCode, time.Since(startedAt))
+
+		return response, err
+	}
+}
+
+// route is the endpoint template or the url path when the template is not set
+func (r *HttpRequest) route() string {
+	if len(r.Endpoint) > 0 {
+		return r.Endpoint
+	}
+
+	return mustParseUrl(r.Url).Path
+}
+
+//
+
+// DefaultPrometheusBuckets are the histogram buckets in seconds used by NewPrometheusMetrics
+var DefaultPrometheusBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60}
+
+// PrometheusMetrics collects the measurements in memory and exposes them in the prometheus text format,
+// it can be mounted as an http handler or written with WriteTo
+type PrometheusMetrics struct {
+	namespace string
+	buckets   []float64
+
+	lock          sync.Mutex
+	requests      map[[3]string]uint64
+	latencies     map[[2]string]*prometheusHistogram
+	rateLimitWait *prometheusHistogram
+	cache         map[[2]string]uint64
+}
+
+type prometheusHistogram struct {
+	counts []uint64 // per bucket, not cumulative
+	count  uint64
+	sum    float64
+}
+
+func NewPrometheusMetrics(namespace string) *PrometheusMetrics {
+	return &PrometheusMetrics{
+		namespace:     namespace,
+		buckets:       DefaultPrometheusBuckets,
+		requests:      make(map[[3]string]uint64, 0),
+		latencies:     make(map[[2]string]*prometheusHistogram, 0),
+		rateLimitWait: newPrometheusHistogram(DefaultPrometheusBuckets),
+		cache:         make(map[[2]string]uint64, 0),
+	}
+}
+
+func newPrometheusHistogram(buckets []float64) *prometheusHistogram {
+	return &prometheusHistogram{
+		counts: make([]uint64, len(buckets)),
+	}
+}
+
+func (h *prometheusHistogram) observe(buckets []float64, value float64) {
+	for i, bucket := range buckets {
+		if value <= bucket {
+			h.counts[i]++
+			break
+		}
+	}
+	h.count++
+	h.sum += value
+}
+
+func (p *PrometheusMetrics) ObserveRequest(method, endpoint string, statusCode int, duration time.Duration) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.requests[[3]string{method, endpoint, strconv.Itoa(statusCode)}]++
+
+	latency, present := p.latencies[[2]string{method, endpoint}]
+	if !present {
+		latency = newPrometheusHistogram(p.buckets)
+		p.latencies[[2]string{method, endpoint}] = latency
+	}
+	latency.observe(p.buckets, duration.Seconds())
+}
+
+func (p *PrometheusMetrics) ObserveRateLimitWait(duration time.Duration) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.rateLimitWait.observe(p.buckets, duration.Seconds())
+}
+
+func (p *PrometheusMetrics) ObserveCache(cache string, isHit bool) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	result := "miss"
+	if isHit {
+		result = "hit"
+	}
+	p.cache[[2]string{cache, result}]++
+}
+
+// WriteTo writes all metrics in the prometheus text exposition format
+func (p *PrometheusMetrics) WriteTo(w io.Writer) (written int64, err error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	buffer := &bytes.Buffer{}
+
+	name := p.metricName("requests_total")
+	fmt.Fprintf(buffer, "# HELP %s Requests sent to the neaktor api, including retries.\n", name)
+	fmt.Fprintf(buffer, "# TYPE %s counter\n", name)
+	requestKeys := make([][3]string, 0, len(p.requests))
+	for key := range p.requests {
+		requestKeys = append(requestKeys, key)
+	}
+	sort.Slice(requestKeys, func(i, j int) bool {
+		return strings.Join(requestKeys[i][:], " ") < strings.Join(requestKeys[j][:], " ")
+	})
+	for _, key := range requestKeys {
+		fmt.Fprintf(buffer, "%s{method=%q,endpoint=%q,code=%q} %d\n", name, key[0], key[1], key[2], p.requests[key])
+	}
+
+	name = p.metricName("request_duration_seconds")
+	fmt.Fprintf(buffer, "# HELP %s Neaktor api request latency, without the rate limit wait.\n", name)
+	fmt.Fprintf(buffer, "# TYPE %s histogram\n", name)
+	latencyKeys := make([][2]string, 0, len(p.latencies))
+	for key := range p.latencies {
+		latencyKeys = append(latencyKeys, key)
+	}
+	sort.Slice(latencyKeys, func(i, j int) bool {
+		return strings.Join(latencyKeys[i][:], " ") < strings.Join(latencyKeys[j][:], " ")
+	})
+	for _, key := range latencyKeys {
+		p.writeHistogram(buffer, name, fmt.Sprintf("method=%q,endpoint=%q", key[0], key[1]), p.latencies[key])
+	}
+
+	name = p.metricName("rate_limit_wait_seconds")
+	fmt.Fprintf(buffer, "# HELP %s Time spent waiting for the api rate limiter.\n", name)
+	fmt.Fprintf(buffer, "# TYPE %s histogram\n", name)
+	p.writeHistogram(buffer, name, "", p.rateLimitWait)
+
+	name = p.metricName("cache_lookups_total")
+	fmt.Fprintf(buffer, "# HELP %s Model, custom field and assignee cache lookups.\n", name)
+	fmt.Fprintf(buffer, "# TYPE %s counter\n", name)
+	cacheKeys := make([][2]string, 0, len(p.cache))
+	for key := range p.cache {
+		cacheKeys = append(cacheKeys, key)
+	}
+	sort.Slice(cacheKeys, func(i, j int) bool {
+		return strings.Join(cacheKeys[i][:], " ") < strings.Join(cacheKeys[j][:], " ")
+	})
+	for _, key := range cacheKeys {
+		fmt.Fprintf(buffer, "%s{cache=%q,result=%q} %d\n", name, key[0], key[1], p.cache[key])
+	}
+
+	return buffer.WriteTo(w)
+}
+
+func (p *PrometheusMetrics) ServeHTTP(w nethttp.ResponseWriter, r *nethttp.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_, _ = p.WriteTo(w)
+}
+
+func (p *PrometheusMetrics) metricName(name string) string {
+	if len(p.namespace) > 0 {
+		return p.namespace + "_" + name
+	}
+
+	return name
+}
+
+func (p *PrometheusMetrics) writeHistogram(w io.Writer, name, labels string, histogram *prometheusHistogram) {
+	separator := ""
+	if len(labels) > 0 {
+		separator = ","
+	}
+
+	cumulative := uint64(0)
+	for i, bucket := range p.buckets {
+		cumulative += histogram.counts[i]
+		fmt.Fprintf(w, "%s_bucket{%s%sle=%q} %d\n", name, labels, separator, strconv.FormatFloat(bucket, 'g', -1, 64), cumulative)
+	}
+	fmt.Fprintf(w, "%s_bucket{%s%sle=\"+Inf\"} %d\n", name, labels, separator, histogram.count)
+
+	if len(labels) > 0 {
+		labels = "{" + labels + "}"
+	}
+	fmt.Fprintf(w, "%s_sum%s %s\n", name, labels, strconv.FormatFloat(histogram.sum, 'g', -1, 64))
+	fmt.Fprintf(w, "%s_count%s %d\n", name, labels, histogram.count)
+}
@@ -0,0 +1,45 @@
+package neaktor_api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	requrl "github.com/wangluozhe/requests/url"
+)
+
+func TestNeaktorApiMetrics(t *testing.T) {
+	httpDoer := HttpDoerFunc(func(ctx context.Context, request *HttpRequest) (*HttpResponse, error) {
+		return &HttpResponse{
+			StatusCode: http.StatusOK,
+			Body:       []byte(`{"data":[{"id":"m1","name":"Заказ"}],"total":1}`),
+		}, nil
+	})
+
+	metrics := NewPrometheusMetrics("neaktor")
+	neaktor := NewNeaktor(*requrl.NewRequest(), "t1o2k3e4n5", 6000, WithHttpDoer(httpDoer), WithMetrics(metrics))
+
+	for i := 0; i < 2; i++ {
+		if _, err := neaktor.GetModelByTitle("Заказ"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	recorder := httptest.NewRecorder()
+	metrics.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	for _, line := range []string{
+		`neaktor_requests_total{method="GET",endpoint="/v1/taskmodels",code="200"} 1`,
+		`neaktor_request_duration_seconds_count{method="GET",endpoint="/v1/taskmodels"} 1`,
+		`neaktor_request_duration_seconds_bucket{method="GET",endpoint="/v1/taskmodels",le="+Inf"} 1`,
+		`neaktor_rate_limit_wait_seconds_count 1`,
+		`neaktor_cache_lookups_total{cache="model",result="hit"} 1`,
+		`neaktor_cache_lookups_total{cache="model",result="miss"} 1`,
+	} {
+		if !strings.Contains(recorder.Body.String(), line+"\n") {
+			t.Errorf("missing %q in:\n%s", line, recorder.Body.String())
+		}
+	}
+}
@@ -1,12 +1,12 @@
 package neaktor_api
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"github.com/wangluozhe/requests"
-	requrl "github.com/wangluozhe/requests/url"
-	"math"
+	nethttp "net/http"
 	"strconv"
 	"strings"
 	"sync"
@@ -17,6 +17,7 @@ type ModelField struct {
 	Id    string
 	Name  string
 	State string
+	Type  FieldType
 }
 
 type ModelStatus struct {
@@ -37,6 +38,7 @@ type CustomFieldOption struct {
 }
 type ModelCustomFieldCache struct {
 	lastUpdatedAt      time.Time
+	fieldType          FieldType
 	customFieldOptions []CustomFieldOption
 }
 
@@ -82,27 +84,76 @@ type IModel interface {
 	GetField(title string) (field ModelField, err error)
 	MustGetField(title string) (field ModelField)
 	GetCustomFieldOptionId(field ModelField, value string) (optionId string, err error)
+	GetCustomFieldOptionIdWithContext(ctx context.Context, field ModelField, value string) (optionId string, err error)
 	MustGetCustomFieldOptionId(field ModelField, value string) (optionId string)
 	GetCustomFieldValue(field ModelField, optionId string) (value string, err error)
+	GetCustomFieldValueWithContext(ctx context.Context, field ModelField, optionId string) (value string, err error)
 	MustGetCustomFieldValue(field ModelField, optionId string) (value string)
+	GetFieldType(field ModelField) (fieldType FieldType, err error)
+	GetFieldTypeWithContext(ctx context.Context, field ModelField) (fieldType FieldType, err error)
+	MustGetFieldType(field ModelField) (fieldType FieldType)
+	GetFieldOptions(field ModelField) (options []CustomFieldOption, err error)
+	GetFieldOptionsWithContext(ctx context.Context, field ModelField) (options []CustomFieldOption, err error)
+	MustGetFieldOptions(field ModelField) (options []CustomFieldOption)
 	GetAssignee(status ModelStatus, name string) (assignee ModelAssignee, err error)
+	GetAssigneeWithContext(ctx context.Context, status ModelStatus, name string) (assignee ModelAssignee, err error)
 	MustGetAssignee(status ModelStatus, name string) (assignee ModelAssignee)
 	GetTasksByStatus(status ModelStatus) (tasks []ITask, err error)
+	GetTasksByStatusWithContext(ctx context.Context, status ModelStatus) (tasks []ITask, err error)
 	MustGetTasksByStatus(status ModelStatus) (tasks []ITask)
 	GetTasksByStatuses(statuses []ModelStatus) (tasks []ITask, err error)
+	GetTasksByStatusesWithContext(ctx context.Context, statuses []ModelStatus) (tasks []ITask, err error)
 	MustGetTasksByStatuses(statuses []ModelStatus) (tasks []ITask)
 	GetTasksByStatusAndFields(status ModelStatus, fields []TaskField) (tasks []ITask, err error)
+	GetTasksByStatusAndFieldsWithContext(ctx context.Context, status ModelStatus, fields []TaskField) (tasks []ITask, err error)
 	MustGetTasksByStatusAndFields(status ModelStatus, fields []TaskField) (tasks []ITask)
 	GetTasksByFields(fields []TaskField) (tasks []ITask, err error)
+	GetTasksByFieldsWithContext(ctx context.Context, fields []TaskField) (tasks []ITask, err error)
 	MustGetTasksByFields(fields []TaskField) (tasks []ITask)
+	IterateTasksByStatus(status ModelStatus) ITaskIterator
+	IterateTasksByStatusWithContext(ctx context.Context, status ModelStatus) ITaskIterator
+	IterateTasksByStatuses(statuses []ModelStatus) ITaskIterator
+	IterateTasksByStatusesWithContext(ctx context.Context, statuses []ModelStatus) ITaskIterator
+	IterateTasksByStatusAndFields(status ModelStatus, fields []TaskField) ITaskIterator
+	IterateTasksByStatusAndFieldsWithContext(ctx context.Context, status ModelStatus, fields []TaskField) ITaskIterator
+	IterateTasksByFields(fields []TaskField) ITaskIterator
+	IterateTasksByFieldsWithContext(ctx context.Context, fields []TaskField) ITaskIterator
+	ScanTasksByStatus(status ModelStatus, scanOptions ScanOptions) ITaskScan
+	ScanTasksByStatusWithContext(ctx context.Context, status ModelStatus, scanOptions ScanOptions) ITaskScan
+	ScanTasksByStatusAndFields(status ModelStatus, fields []TaskField, scanOptions ScanOptions) ITaskScan
+	ScanTasksByStatusAndFieldsWithContext(ctx context.Context, status ModelStatus, fields []TaskField, scanOptions ScanOptions) ITaskScan
 	GetTaskById(id int) (task ITask, err error)
+	GetTaskByIdWithContext(ctx context.Context, id int) (task ITask, err error)
 	MustGetTaskById(id int) (task ITask)
+	Query() *TaskQuery
+	IterateTasks(query *TaskQuery) ITaskIterator
+	IterateTasksWithContext(ctx context.Context, query *TaskQuery) ITaskIterator
+	ScanTasks(query *TaskQuery, scanOptions ScanOptions) ITaskScan
+	ScanTasksWithContext(ctx context.Context, query *TaskQuery, scanOptions ScanOptions) ITaskScan
+	CountTasks(query *TaskQuery) (count int, err error)
+	CountTasksWithContext(ctx context.Context, query *TaskQuery) (count int, err error)
+	CountTasksByStatus(status ModelStatus) (count int, err error)
+	CountTasksByStatusWithContext(ctx context.Context, status ModelStatus) (count int, err error)
+	CountTasksByStatuses(statuses []ModelStatus) (count int, err error)
+	CountTasksByStatusesWithContext(ctx context.Context, statuses []ModelStatus) (count int, err error)
+	CountTasksByStatusAndFields(status ModelStatus, fields []TaskField) (count int, err error)
+	CountTasksByStatusAndFieldsWithContext(ctx context.Context, status ModelStatus, fields []TaskField) (count int, err error)
+	CountTasksByFields(fields []TaskField) (count int, err error)
+	CountTasksByFieldsWithContext(ctx context.Context, fields []TaskField) (count int, err error)
 	IsTasksByStatusExists(status ModelStatus) (isExists bool, err error)
+	IsTasksByStatusExistsWithContext(ctx context.Context, status ModelStatus) (isExists bool, err error)
 	IsTasksByStatusesExists(statuses []ModelStatus) (isExists bool, err error)
+	IsTasksByStatusesExistsWithContext(ctx context.Context, statuses []ModelStatus) (isExists bool, err error)
 	IsTasksByStatusAndFieldsExists(status ModelStatus, fields []TaskField) (isExists bool, err error)
+	IsTasksByStatusAndFieldsExistsWithContext(ctx context.Context, status ModelStatus, fields []TaskField) (isExists bool, err error)
 	IsTasksByFieldsExists(fields []TaskField) (isExists bool, err error)
+	IsTasksByFieldsExistsWithContext(ctx context.Context, fields []TaskField) (isExists bool, err error)
 	CreateTask(assignee ModelAssignee, fields []TaskField) (task ITask, err error)
+	CreateTaskWithContext(ctx context.Context, assignee ModelAssignee, fields []TaskField) (task ITask, err error)
 	MustCreateTask(assignee ModelAssignee, fields []TaskField) (task ITask)
+	UpdateTaskFields(id int, fields []TaskField) (err error)
+	UpdateTaskFieldsWithContext(ctx context.Context, id int, fields []TaskField) (err error)
+	MustUpdateTaskFields(id int, fields []TaskField)
 }
 
 func NewModel(neaktor *Neaktor, id string, statuses map[string]ModelStatus, fields map[string]ModelField) IModel {
@@ -118,6 +169,45 @@ func NewModel(neaktor *Neaktor, id string, statuses map[string]ModelStatus, fiel
 	}
 }
 
+func NewCustomFieldOption(id string, value string) CustomFieldOption {
+	return CustomFieldOption{
+		id:    id,
+		value: value,
+	}
+}
+
+func (o CustomFieldOption) GetId() string {
+	return o.id
+}
+
+func (o CustomFieldOption) GetValue() string {
+	return o.value
+}
+
+func NewModelAssignee(id int, name string, typeOf string) ModelAssignee {
+	return ModelAssignee{
+		id:     id,
+		name:   name,
+		typeOf: typeOf,
+	}
+}
+
+func (a ModelAssignee) GetId() int {
+	return a.id
+}
+
+func (a ModelAssignee) GetName() string {
+	return a.name
+}
+
+func (a ModelAssignee) GetType() string {
+	return a.typeOf
+}
+
+func (m *Model) attribute() Attribute {
+	return Attribute{Key: AttributeModelId, Value: m.id}
+}
+
 func (m *Model) GetId() string {
 	return m.id
 }
@@ -227,106 +317,103 @@ func (m *Model) MustGetField(title string) (field ModelField) {
 }
 
 func (m *Model) GetCustomFieldOptionId(field ModelField, value string) (optionId string, err error) {
-	type OptionsAvailableValues struct {
-		Id    string `json:"id"`
-		Value string `json:"value"`
-	}
+	return m.GetCustomFieldOptionIdWithContext(context.Background(), field, value)
+}
 
-	type CustomFieldsResponseOptions struct {
-		AvailableValues []OptionsAvailableValues `json:"availableValues"`
-	}
-
-	type CustomFieldsResponse struct {
-		NeaktorErrorResponse
-		Id      string                      `json:"id"`
-		Type    string                      `json:"type"`
-		Name    string                      `json:"name"`
-		Options CustomFieldsResponseOptions `json:"options"`
-	}
+func (m *Model) GetCustomFieldOptionIdWithContext(ctx context.Context, field ModelField, value string) (optionId string, err error) {
+	ctx, span := m.neaktor.startSpan(ctx, "Model.GetCustomFieldOptionId", m.attribute(), Attribute{Key: AttributeFieldId, Value: field.Id})
+	defer func() { endSpan(span, err) }()
 
 	m.modelCustomFieldCacheLock.Lock()
 	defer m.modelCustomFieldCacheLock.Unlock()
 
-	// cache first
+	option, isFound, err := m.findCustomFieldOption(ctx, field, func(option CustomFieldOption) bool {
+		return option.value == value
+	})
+	if err != nil {
+		return optionId, err
+	}
+	if !isFound {
+		return optionId, ErrModelCustomFieldOptionNotFound
+	}
 
-	if cachedModelCustomField, present := m.modelCustomFieldCacheMap[field.Id]; present {
-		if time.Now().Before(cachedModelCustomField.lastUpdatedAt.Add(ModelCacheTime)) {
-			for _, customFieldOption := range cachedModelCustomField.customFieldOptions {
-				if customFieldOption.value == value {
-					return customFieldOption.id, err
-				}
-			}
-		}
+	return option.id, err
+}
 
-		delete(m.modelCustomFieldCacheMap, field.Id)
+func (m *Model) MustGetCustomFieldOptionId(field ModelField, value string) (optionId string) {
+	var err error
+	optionId, err = m.GetCustomFieldOptionId(field, value)
+	if err != nil {
+		panic(err)
 	}
 
-	// request second
+	return optionId
+}
 
-	httpClient := m.neaktor.httpClient
+func (m *Model) GetCustomFieldValue(field ModelField, optionId string) (value string, err error) {
+	return m.GetCustomFieldValueWithContext(context.Background(), field, optionId)
+}
 
-	httpClient.Headers = requrl.NewHeaders()
-	httpClient.Headers.Add("Authorization", m.neaktor.token)
+func (m *Model) GetCustomFieldValueWithContext(ctx context.Context, field ModelField, optionId string) (value string, err error) {
+	ctx, span := m.neaktor.startSpan(ctx, "Model.GetCustomFieldValue", m.attribute(), Attribute{Key: AttributeFieldId, Value: field.Id})
+	defer func() { endSpan(span, err) }()
 
-	response, err := requests.Get(mustUrlJoinPath(ApiGateway, "customfields", field.Id), &httpClient)
-	if err != nil {
-		return optionId, fmt.Errorf("/v1/customfields/%s request error: %w", field.Id, err)
-	}
+	m.modelCustomFieldCacheLock.Lock()
+	defer m.modelCustomFieldCacheLock.Unlock()
 
-	if response.StatusCode >= 500 {
-		m.neaktor.log.Debugf("response status code: %d", response.StatusCode)
-		return optionId, fmt.Errorf("service unavailable, code: %d", response.StatusCode)
+	option, isFound, err := m.findCustomFieldOption(ctx, field, func(option CustomFieldOption) bool {
+		return option.id == optionId
+	})
+	if err != nil {
+		return value, err
 	}
-
-	var customFieldsResponses []CustomFieldsResponse
-	if err := json.Unmarshal(response.Content, &customFieldsResponses); err != nil {
-		m.neaktor.log.Debugf("response code: %d, response body: %v", response.StatusCode, response.Text)
-		return optionId, fmt.Errorf("unmarshaling error: %w", err)
+	if !isFound {
+		return value, ErrModelCustomFieldValueNotFound
 	}
-	//if len(createTaskResponse.Code) > 0 {
-	//	return task, parseErrorCode(createTaskResponse.Code, createTaskResponse.Message)
-	//}
 
-	for _, cutomField := range customFieldsResponses {
-		customFieldOptions := make([]CustomFieldOption, 0)
+	return option.value, err
+}
 
-		for _, item := range cutomField.Options.AvailableValues {
-			customFieldOptions = append(customFieldOptions, CustomFieldOption{
-				id:    item.Id,
-				value: item.Value,
-			})
-		}
+// findCustomFieldOption looks the option up in the cache, the options are requested again when the option is missing,
+// it may have been added after they were cached. The cache lock must be held.
+func (m *Model) findCustomFieldOption(ctx context.Context, field ModelField, isMatched func(option CustomFieldOption) bool) (option CustomFieldOption, isFound bool, err error) {
+	customField, isCached, err := m.loadCustomField(ctx, field, false)
+	if err != nil {
+		return option, isFound, err
+	}
 
-		m.modelCustomFieldCacheMap[field.Id] = ModelCustomFieldCache{
-			lastUpdatedAt:      time.Now(),
-			customFieldOptions: customFieldOptions,
+	for _, customFieldOption := range customField.customFieldOptions {
+		if isMatched(customFieldOption) {
+			m.neaktor.metrics.ObserveCache(CacheCustomField, isCached)
+			return customFieldOption, true, err
 		}
 	}
 
-	//
+	m.neaktor.metrics.ObserveCache(CacheCustomField, false)
 
-	if cachedModelCustomField, present := m.modelCustomFieldCacheMap[field.Id]; present {
-		for _, customFieldOption := range cachedModelCustomField.customFieldOptions {
-			if customFieldOption.value == value {
-				return customFieldOption.id, err
-			}
-		}
+	// a field with no options is not a select field, there is nothing to request again
+	if !isCached || len(customField.customFieldOptions) <= 0 {
+		return option, isFound, err
 	}
 
-	return optionId, ErrModelCustomFieldOptionNotFound
-}
-
-func (m *Model) MustGetCustomFieldOptionId(field ModelField, value string) (optionId string) {
-	var err error
-	optionId, err = m.GetCustomFieldOptionId(field, value)
+	customField, _, err = m.loadCustomField(ctx, field, true)
 	if err != nil {
-		panic(err)
+		return option, isFound, err
 	}
 
-	return optionId
+	for _, customFieldOption := range customField.customFieldOptions {
+		if isMatched(customFieldOption) {
+			return customFieldOption, true, err
+		}
+	}
+
+	return option, isFound, err
 }
 
-func (m *Model) GetCustomFieldValue(field ModelField, optionId string) (value string, err error) {
+// loadCustomField returns the field options, from the cache unless it is stale or isReload is set. A field the api
+// doesn't know as a custom field, it responds with 404, is cached with no options, so it isn't requested again until
+// the cache expires. The cache lock must be held.
+func (m *Model) loadCustomField(ctx context.Context, field ModelField, isReload bool) (customField ModelCustomFieldCache, isCached bool, err error) {
 	type OptionsAvailableValues struct {
 		Id    string `json:"id"`
 		Value string `json:"value"`
@@ -344,76 +431,40 @@ func (m *Model) GetCustomFieldValue(field ModelField, optionId string) (value st
 		Options CustomFieldsResponseOptions `json:"options"`
 	}
 
-	m.modelCustomFieldCacheLock.Lock()
-	defer m.modelCustomFieldCacheLock.Unlock()
-
 	// cache first
 
-	if cachedModelCustomField, present := m.modelCustomFieldCacheMap[field.Id]; present {
-		if time.Now().Before(cachedModelCustomField.lastUpdatedAt.Add(ModelCacheTime)) {
-			for _, customFieldOption := range cachedModelCustomField.customFieldOptions {
-				if customFieldOption.id == optionId {
-					return customFieldOption.value, err
-				}
-			}
-		}
-
-		delete(m.modelCustomFieldCacheMap, field.Id)
+	if cachedModelCustomField, isCached := m.cachedCustomField(field.Id); isCached && !isReload {
+		return cachedModelCustomField, true, err
 	}
 
 	// request second
 
-	httpClient := m.neaktor.httpClient
+	request := m.neaktor.newHttpRequest(nethttp.MethodGet, "/v1/customfields/{id}", mustUrlJoinPath(m.neaktor.apiGateway, "customfields", field.Id))
 
-	httpClient.Headers = requrl.NewHeaders()
-	httpClient.Headers.Add("Authorization", m.neaktor.token)
-
-	response, err := requests.Get(mustUrlJoinPath(ApiGateway, "customfields", field.Id), &httpClient)
-	if err != nil {
-		return value, fmt.Errorf("/v1/customfields/%s request error: %w", field.Id, err)
-	}
-
-	if response.StatusCode >= 500 {
-		m.neaktor.log.Debugf("response status code: %d", response.StatusCode)
-		return value, fmt.Errorf("service unavailable, code: %d", response.StatusCode)
+	var customFieldsResponses []CustomFieldsResponse
+	if err := m.neaktor.call(ctx, request, &customFieldsResponses); err != nil && !errors.Is(err, ErrCode404) {
+		return customField, isCached, err
 	}
 
-	var customFieldsResponses []CustomFieldsResponse
-	if err := json.Unmarshal(response.Content, &customFieldsResponses); err != nil {
-		m.neaktor.log.Debugf("response code: %d, response body: %v", response.StatusCode, response.Text)
-		return value, fmt.Errorf("unmarshaling error: %w", err)
+	customField = ModelCustomFieldCache{
+		lastUpdatedAt:      time.Now(),
+		customFieldOptions: make([]CustomFieldOption, 0),
 	}
-	//if len(createTaskResponse.Code) > 0 {
-	//	return task, parseErrorCode(createTaskResponse.Code, createTaskResponse.Message)
-	//}
 
 	for _, cutomField := range customFieldsResponses {
-		customFieldOptions := make([]CustomFieldOption, 0)
+		customField.fieldType = FieldType(cutomField.Type)
 
 		for _, item := range cutomField.Options.AvailableValues {
-			customFieldOptions = append(customFieldOptions, CustomFieldOption{
+			customField.customFieldOptions = append(customField.customFieldOptions, CustomFieldOption{
 				id:    item.Id,
 				value: item.Value,
 			})
 		}
-
-		m.modelCustomFieldCacheMap[field.Id] = ModelCustomFieldCache{
-			lastUpdatedAt:      time.Now(),
-			customFieldOptions: customFieldOptions,
-		}
 	}
 
-	//
+	m.modelCustomFieldCacheMap[field.Id] = customField
 
-	if cachedModelCustomField, present := m.modelCustomFieldCacheMap[field.Id]; present {
-		for _, customFieldOption := range cachedModelCustomField.customFieldOptions {
-			if customFieldOption.id == optionId {
-				return customFieldOption.value, err
-			}
-		}
-	}
-
-	return value, ErrModelCustomFieldValueNotFound
+	return customField, isCached, err
 }
 
 func (m *Model) MustGetCustomFieldValue(field ModelField, optionId string) (value string) {
@@ -426,7 +477,80 @@ func (m *Model) MustGetCustomFieldValue(field ModelField, optionId string) (valu
 	return value
 }
 
+func (m *Model) GetFieldType(field ModelField) (fieldType FieldType, err error) {
+	return m.GetFieldTypeWithContext(context.Background(), field)
+}
+
+// GetFieldTypeWithContext returns the type loaded with the model, the fields loaded without one are requested as custom
+// fields, FieldTypeUnknown is returned when the api doesn't know the field as a custom one
+func (m *Model) GetFieldTypeWithContext(ctx context.Context, field ModelField) (fieldType FieldType, err error) {
+	ctx, span := m.neaktor.startSpan(ctx, "Model.GetFieldType", m.attribute(), Attribute{Key: AttributeFieldId, Value: field.Id})
+	defer func() { endSpan(span, err) }()
+
+	if field.Type != FieldTypeUnknown {
+		return field.Type, err
+	}
+
+	m.modelCustomFieldCacheLock.Lock()
+	defer m.modelCustomFieldCacheLock.Unlock()
+
+	return m.loadFieldType(ctx, field)
+}
+
+func (m *Model) MustGetFieldType(field ModelField) (fieldType FieldType) {
+	var err error
+	fieldType, err = m.GetFieldType(field)
+	if err != nil {
+		panic(err)
+	}
+
+	return fieldType
+}
+
+func (m *Model) GetFieldOptions(field ModelField) (options []CustomFieldOption, err error) {
+	return m.GetFieldOptionsWithContext(context.Background(), field)
+}
+
+// GetFieldOptionsWithContext returns the options of a select field, the other fields have no options
+func (m *Model) GetFieldOptionsWithContext(ctx context.Context, field ModelField) (options []CustomFieldOption, err error) {
+	ctx, span := m.neaktor.startSpan(ctx, "Model.GetFieldOptions", m.attribute(), Attribute{Key: AttributeFieldId, Value: field.Id})
+	defer func() { endSpan(span, err) }()
+
+	if field.Type != FieldTypeUnknown && field.Type != FieldTypeSelect {
+		return options, err
+	}
+
+	m.modelCustomFieldCacheLock.Lock()
+	defer m.modelCustomFieldCacheLock.Unlock()
+
+	customField, isCached, err := m.loadCustomField(ctx, field, false)
+	if err != nil {
+		return options, err
+	}
+
+	m.neaktor.metrics.ObserveCache(CacheCustomField, isCached)
+
+	return append(options, customField.customFieldOptions...), err
+}
+
+func (m *Model) MustGetFieldOptions(field ModelField) (options []CustomFieldOption) {
+	var err error
+	options, err = m.GetFieldOptions(field)
+	if err != nil {
+		panic(err)
+	}
+
+	return options
+}
+
 func (m *Model) GetAssignee(status ModelStatus, name string) (assignee ModelAssignee, err error) {
+	return m.GetAssigneeWithContext(context.Background(), status, name)
+}
+
+func (m *Model) GetAssigneeWithContext(ctx context.Context, status ModelStatus, name string) (assignee ModelAssignee, err error) {
+	ctx, span := m.neaktor.startSpan(ctx, "Model.GetAssignee", m.attribute(), Attribute{Key: AttributeStatusId, Value: status.Id})
+	defer func() { endSpan(span, err) }()
+
 	type RoutingResponseAssignee struct {
 		Id   int    `json:"id"`
 		Name string `json:"name"`
@@ -449,6 +573,7 @@ func (m *Model) GetAssignee(status ModelStatus, name string) (assignee ModelAssi
 		if time.Now().Before(cachedModelAssignee.lastUpdatedAt.Add(ModelCacheTime)) {
 			for _, modelAssignee := range cachedModelAssignee.modelAssignees {
 				if modelAssignee.name == name {
+					m.neaktor.metrics.ObserveCache(CacheAssignee, true)
 					return modelAssignee, err
 				}
 			}
@@ -457,31 +582,16 @@ func (m *Model) GetAssignee(status ModelStatus, name string) (assignee ModelAssi
 		delete(m.modelAssigneeCacheMap, status.Id)
 	}
 
-	// request second
-
-	httpClient := m.neaktor.httpClient
+	m.neaktor.metrics.ObserveCache(CacheAssignee, false)
 
-	httpClient.Headers = requrl.NewHeaders()
-	httpClient.Headers.Add("Authorization", m.neaktor.token)
-
-	response, err := requests.Get(mustUrlJoinPath(ApiGateway, "taskmodels", m.id, status.Id, "routings"), &httpClient)
-	if err != nil {
-		return assignee, fmt.Errorf("/v1/taskmodels/%s/%s/routings request error: %w", m.id, status.Id, err)
-	}
+	// request second
 
-	if response.StatusCode >= 500 {
-		m.neaktor.log.Debugf("response status code: %d", response.StatusCode)
-		return assignee, fmt.Errorf("service unavailable, code: %d", response.StatusCode)
-	}
+	request := m.neaktor.newHttpRequest(nethttp.MethodGet, "/v1/taskmodels/{model_id}/{status_id}/routings", mustUrlJoinPath(m.neaktor.apiGateway, "taskmodels", m.id, status.Id, "routings"))
 
 	var routingResponses []RoutingResponse
-	if err := json.Unmarshal(response.Content, &routingResponses); err != nil {
-		m.neaktor.log.Debugf("response code: %d, response body: %v", response.StatusCode, response.Text)
-		return assignee, fmt.Errorf("unmarshaling error: %w", err)
+	if err := m.neaktor.call(ctx, request, &routingResponses); err != nil {
+		return assignee, err
 	}
-	//if len(createTaskResponse.Code) > 0 {
-	//	return task, parseErrorCode(createTaskResponse.Code, createTaskResponse.Message)
-	//}
 
 	for _, routing := range routingResponses {
 		modelAssignees := make([]ModelAssignee, 0)
@@ -525,164 +635,191 @@ func (m *Model) MustGetAssignee(status ModelStatus, name string) (assignee Model
 
 //
 
-func (m *Model) IsTasksByStatusExists(status ModelStatus) (isExists bool, err error) {
-	tasks, err := m.GetTasksByStatus(status)
-	if err != nil {
-		return isExists, err
-	}
+func (m *Model) CountTasksByStatus(status ModelStatus) (count int, err error) {
+	return m.CountTasksByStatusWithContext(context.Background(), status)
+}
+
+func (m *Model) CountTasksByStatusWithContext(ctx context.Context, status ModelStatus) (count int, err error) {
+	ctx, span := m.neaktor.startSpan(ctx, "Model.CountTasksByStatus", m.attribute(), Attribute{Key: AttributeStatusId, Value: status.Id})
+	defer func() { endSpan(span, err) }()
 
-	return len(tasks) > 0, err
+	return m.countTasks(ctx, m.Query().Statuses(status))
 }
 
-func (m *Model) IsTasksByStatusesExists(statuses []ModelStatus) (isExists bool, err error) {
-	tasks, err := m.GetTasksByStatuses(statuses)
-	if err != nil {
-		return isExists, err
+func (m *Model) CountTasksByStatuses(statuses []ModelStatus) (count int, err error) {
+	return m.CountTasksByStatusesWithContext(context.Background(), statuses)
+}
+
+func (m *Model) CountTasksByStatusesWithContext(ctx context.Context, statuses []ModelStatus) (count int, err error) {
+	ctx, span := m.neaktor.startSpan(ctx, "Model.CountTasksByStatuses", m.attribute())
+	defer func() { endSpan(span, err) }()
+
+	// no statuses are no tasks here, unlike a query without statuses
+	if len(statuses) <= 0 {
+		return count, err
 	}
 
-	return len(tasks) > 0, err
+	return m.countTasks(ctx, m.Query().Statuses(statuses...))
 }
 
-func (m *Model) IsTasksByStatusAndFieldsExists(status ModelStatus, fields []TaskField) (isExists bool, err error) {
-	tasks, err := m.GetTasksByStatusAndFields(status, fields)
-	if err != nil {
-		return isExists, err
-	}
+func (m *Model) CountTasksByStatusAndFields(status ModelStatus, fields []TaskField) (count int, err error) {
+	return m.CountTasksByStatusAndFieldsWithContext(context.Background(), status, fields)
+}
+
+func (m *Model) CountTasksByStatusAndFieldsWithContext(ctx context.Context, status ModelStatus, fields []TaskField) (count int, err error) {
+	ctx, span := m.neaktor.startSpan(ctx, "Model.CountTasksByStatusAndFields", m.attribute(), Attribute{Key: AttributeStatusId, Value: status.Id})
+	defer func() { endSpan(span, err) }()
 
-	return len(tasks) > 0, err
+	return m.countTasks(ctx, m.Query().Statuses(status).WhereFields(fields))
 }
 
-func (m *Model) IsTasksByFieldsExists(fields []TaskField) (isExists bool, err error) {
-	tasks, err := m.GetTasksByFields(fields)
-	if err != nil {
-		return isExists, err
-	}
+func (m *Model) CountTasksByFields(fields []TaskField) (count int, err error) {
+	return m.CountTasksByFieldsWithContext(context.Background(), fields)
+}
 
-	return len(tasks) > 0, err
+func (m *Model) CountTasksByFieldsWithContext(ctx context.Context, fields []TaskField) (count int, err error) {
+	ctx, span := m.neaktor.startSpan(ctx, "Model.CountTasksByFields", m.attribute())
+	defer func() { endSpan(span, err) }()
+
+	return m.countTasks(ctx, m.Query().WhereFields(fields))
 }
 
-func (m *Model) GetTasksByStatus(status ModelStatus) (tasks []ITask, err error) {
-	type DataField struct {
-		Id    string      `json:"id"`
-		Value interface{} `json:"value"`
-		State string      `json:"state"`
-	}
+func (m *Model) CountTasks(query *TaskQuery) (count int, err error) {
+	return m.CountTasksWithContext(context.Background(), query)
+}
+
+func (m *Model) CountTasksWithContext(ctx context.Context, query *TaskQuery) (count int, err error) {
+	ctx, span := m.neaktor.startSpan(ctx, "Model.CountTasks", m.attribute())
+	defer func() { endSpan(span, err) }()
+
+	return m.countTasks(ctx, query)
+}
 
-	type TasksResponseData struct {
-		Id         int           `json:"id"`
-		ProjectId  string        `json:"projectId"`
-		Fields     []DataField   `json:"fields"`
-		Status     string        `json:"status"`
-		ModelId    string        `json:"modelId"`
-		CanDelete  bool          `json:"canDelete"`
-		ModuleId   string        `json:"moduleId"`
-		Idx        string        `json:"idx"`
-		ParentId   interface{}   `json:"parentId"`
-		SubtaskIds []interface{} `json:"subtaskIds"`
+// countTasks requests a single task per status and sums the totals of the responses, the tasks of a query with range
+// filters are fetched and counted instead, the api doesn't filter by ranges
+func (m *Model) countTasks(ctx context.Context, query *TaskQuery) (count int, err error) {
+	if query.isRanged() {
+		countQuery := query.clone()
+		countQuery.sorts = nil
+		countQuery.limit = 0
+
+		iterator := m.newTaskIterator(ctx, countQuery)
+		for iterator.Next() {
+			count++
+		}
+
+		return count, iterator.Err()
 	}
 
-	type TasksResponseLinks struct {
-		Next string `json:"next"`
+	queries, err := m.queryValues(ctx, query)
+	if err != nil {
+		return count, err
 	}
 
-	type TasksResponse struct {
-		NeaktorErrorResponse
-		Data  []TasksResponseData `json:"data"`
-		Links TasksResponseLinks  `json:"links"`
-		Page  int                 `json:"page"`
-		Size  int                 `json:"size"`
-		Total int                 `json:"total"`
+	for _, values := range queries {
+		request := m.neaktor.newHttpRequest(nethttp.MethodGet, "/v1/tasks", mustUrlJoinPath(m.neaktor.apiGateway, "tasks"))
+
+		for key, values := range values {
+			for _, value := range values {
+				request.Params.Add(key, value)
+			}
+		}
+
+		request.Params.Add("model_id", m.id)
+		request.Params.Add("size", "1")
+		request.Params.Add("page", "0")
+
+		var tasksResponse tasksResponse
+		if err := m.neaktor.call(ctx, request, &tasksResponse); err != nil {
+			return count, err
+		}
+
+		count += tasksResponse.Total
 	}
 
-	//
+	return count, err
+}
+
+func (m *Model) IsTasksByStatusExists(status ModelStatus) (isExists bool, err error) {
+	return m.IsTasksByStatusExistsWithContext(context.Background(), status)
+}
 
-	limit := 50
-	maxPages := 1
+func (m *Model) IsTasksByStatusExistsWithContext(ctx context.Context, status ModelStatus) (isExists bool, err error) {
+	ctx, span := m.neaktor.startSpan(ctx, "Model.IsTasksByStatusExists", m.attribute(), Attribute{Key: AttributeStatusId, Value: status.Id})
+	defer func() { endSpan(span, err) }()
 
-	for page := 0; page < maxPages; page++ {
-		m.neaktor.apiLimiter.Take()
+	count, err := m.CountTasksByStatusWithContext(ctx, status)
+	if err != nil {
+		return isExists, err
+	}
 
-		httpClient := m.neaktor.httpClient
+	return count > 0, err
+}
 
-		httpClient.Headers = requrl.NewHeaders()
-		httpClient.Headers.Add("Authorization", m.neaktor.token)
+func (m *Model) IsTasksByStatusesExists(statuses []ModelStatus) (isExists bool, err error) {
+	return m.IsTasksByStatusesExistsWithContext(context.Background(), statuses)
+}
 
-		httpClient.Params = requrl.NewParams()
-		httpClient.Params.Add("model_id", m.id)
-		httpClient.Params.Add("status_id", status.Id)
-		httpClient.Params.Add("size", strconv.Itoa(limit))
-		httpClient.Params.Add("page", strconv.Itoa(page))
+// IsTasksByStatusesExistsWithContext stops at the first status with tasks
+func (m *Model) IsTasksByStatusesExistsWithContext(ctx context.Context, statuses []ModelStatus) (isExists bool, err error) {
+	ctx, span := m.neaktor.startSpan(ctx, "Model.IsTasksByStatusesExists", m.attribute())
+	defer func() { endSpan(span, err) }()
 
-		response, err := requests.Get(mustUrlJoinPath(ApiGateway, "tasks"), &httpClient)
+	for _, status := range statuses {
+		count, err := m.countTasks(ctx, m.Query().Statuses(status))
 		if err != nil {
-			return tasks, fmt.Errorf("/v1/tasks?model_id=%s&status_id=%s&size=%d&page=%d request error: %w", m.id, status.Id, limit, page, err)
+			return isExists, err
 		}
 
-		if response.StatusCode >= 500 {
-			m.neaktor.log.Debugf("response status code: %d", response.StatusCode)
-			return tasks, fmt.Errorf("service unavailable, code: %d", response.StatusCode)
+		if count > 0 {
+			return true, err
 		}
+	}
 
-		var tasksResponse TasksResponse
-		if err := json.Unmarshal(response.Content, &tasksResponse); err != nil {
-			m.neaktor.log.Debugf("response code: %d, response body: %v", response.StatusCode, response.Text)
-			return tasks, fmt.Errorf("unmarshaling error: %w", err)
-		}
-		if len(tasksResponse.Code) > 0 {
-			return tasks, parseErrorCode(tasksResponse.Code, tasksResponse.Message)
-		}
+	return isExists, err
+}
 
-		for _, taskData := range tasksResponse.Data {
-			fields := make([]TaskField, 0)
+func (m *Model) IsTasksByStatusAndFieldsExists(status ModelStatus, fields []TaskField) (isExists bool, err error) {
+	return m.IsTasksByStatusAndFieldsExistsWithContext(context.Background(), status, fields)
+}
 
-			var startDate time.Time
-			var endDate time.Time
-			var statusClosedDate time.Time
+func (m *Model) IsTasksByStatusAndFieldsExistsWithContext(ctx context.Context, status ModelStatus, fields []TaskField) (isExists bool, err error) {
+	ctx, span := m.neaktor.startSpan(ctx, "Model.IsTasksByStatusAndFieldsExists", m.attribute(), Attribute{Key: AttributeStatusId, Value: status.Id})
+	defer func() { endSpan(span, err) }()
 
-			for _, field := range taskData.Fields {
-				if strings.EqualFold(field.Id, "start") && field.Value != nil {
-					startDate, err = time.Parse("02-01-2006T15:04:05", field.Value.(string))
-					if err != nil {
-						return tasks, fmt.Errorf("task start parse error: %w", err)
-					}
-				}
-				if strings.EqualFold(field.Id, "end") && field.Value != nil {
-					endDate, err = time.Parse("02-01-2006T15:04:05", field.Value.(string))
-					if err != nil {
-						return tasks, fmt.Errorf("task end parse error: %w", err)
-					}
-				}
-				if strings.EqualFold(field.Id, "statusClosedDate") && field.Value != nil {
-					statusClosedDate, err = time.Parse("02-01-2006T15:04:05", field.Value.(string))
-					if err != nil {
-						return tasks, fmt.Errorf("task status closed parse error: %w", err)
-					}
-				}
+	count, err := m.CountTasksByStatusAndFieldsWithContext(ctx, status, fields)
+	if err != nil {
+		return isExists, err
+	}
 
-				fields = append(fields, TaskField{
-					ModelField: m.fields[field.Id],
-					Value:      field.Value,
-					State:      field.State,
-				})
-			}
+	return count > 0, err
+}
 
-			var modelStatus ModelStatus
+func (m *Model) IsTasksByFieldsExists(fields []TaskField) (isExists bool, err error) {
+	return m.IsTasksByFieldsExistsWithContext(context.Background(), fields)
+}
 
-			for _, status := range m.statuses {
-				if strings.EqualFold(status.Name, taskData.Status) {
-					modelStatus = status
-				}
-			}
+func (m *Model) IsTasksByFieldsExistsWithContext(ctx context.Context, fields []TaskField) (isExists bool, err error) {
+	ctx, span := m.neaktor.startSpan(ctx, "Model.IsTasksByFieldsExists", m.attribute())
+	defer func() { endSpan(span, err) }()
 
-			tasks = append(tasks, NewTask(m, modelStatus, taskData.Id, taskData.Idx, startDate, endDate, statusClosedDate, fields))
-		}
+	count, err := m.CountTasksByFieldsWithContext(ctx, fields)
+	if err != nil {
+		return isExists, err
+	}
 
-		//
+	return count > 0, err
+}
 
-		maxPages = int(math.Ceil(float64(tasksResponse.Total) / float64(limit)))
-	}
+func (m *Model) GetTasksByStatus(status ModelStatus) (tasks []ITask, err error) {
+	return m.GetTasksByStatusWithContext(context.Background(), status)
+}
+
+func (m *Model) GetTasksByStatusWithContext(ctx context.Context, status ModelStatus) (tasks []ITask, err error) {
+	ctx, span := m.neaktor.startSpan(ctx, "Model.GetTasksByStatus", m.attribute(), Attribute{Key: AttributeStatusId, Value: status.Id})
+	defer func() { endSpan(span, err) }()
 
-	return tasks, err
+	return collectTasks(m.IterateTasksByStatusWithContext(ctx, status))
 }
 
 func (m *Model) MustGetTasksByStatus(status ModelStatus) (tasks []ITask) {
@@ -696,16 +833,14 @@ func (m *Model) MustGetTasksByStatus(status ModelStatus) (tasks []ITask) {
 }
 
 func (m *Model) GetTasksByStatuses(statuses []ModelStatus) (tasks []ITask, err error) {
-	for _, status := range statuses {
-		tasksByStatus, err := m.GetTasksByStatus(status)
-		if err != nil {
-			return tasks, err
-		}
+	return m.GetTasksByStatusesWithContext(context.Background(), statuses)
+}
 
-		tasks = append(tasks, tasksByStatus...)
-	}
+func (m *Model) GetTasksByStatusesWithContext(ctx context.Context, statuses []ModelStatus) (tasks []ITask, err error) {
+	ctx, span := m.neaktor.startSpan(ctx, "Model.GetTasksByStatuses", m.attribute())
+	defer func() { endSpan(span, err) }()
 
-	return tasks, err
+	return collectTasks(m.IterateTasksByStatusesWithContext(ctx, statuses))
 }
 
 func (m *Model) MustGetTasksByStatuses(statuses []ModelStatus) (tasks []ITask) {
@@ -719,163 +854,14 @@ func (m *Model) MustGetTasksByStatuses(statuses []ModelStatus) (tasks []ITask) {
 }
 
 func (m *Model) GetTasksByStatusAndFields(status ModelStatus, fields []TaskField) (tasks []ITask, err error) {
-	type DataField struct {
-		Id    string      `json:"id"`
-		Value interface{} `json:"value"`
-		State string      `json:"state"`
-	}
-
-	type TasksResponseData struct {
-		Id         int           `json:"id"`
-		ProjectId  string        `json:"projectId"`
-		Fields     []DataField   `json:"fields"`
-		Status     string        `json:"status"`
-		ModelId    string        `json:"modelId"`
-		CanDelete  bool          `json:"canDelete"`
-		ModuleId   string        `json:"moduleId"`
-		Idx        string        `json:"idx"`
-		ParentId   interface{}   `json:"parentId"`
-		SubtaskIds []interface{} `json:"subtaskIds"`
-	}
-
-	type TasksResponseLinks struct {
-		Next string `json:"next"`
-	}
-
-	type TasksResponse struct {
-		NeaktorErrorResponse
-		Data  []TasksResponseData `json:"data"`
-		Links TasksResponseLinks  `json:"links"`
-		Page  int                 `json:"page"`
-		Size  int                 `json:"size"`
-		Total int                 `json:"total"`
-	}
-
-	//
-
-	otherParams := requrl.NewParams()
-	for _, field := range fields {
-		var value string
-		switch field.Value.(type) {
-		case string:
-			value = field.Value.(string)
-		case float64:
-			value = fmt.Sprintf("%f", field.Value.(float64))
-		case float32:
-			value = fmt.Sprintf("%f", field.Value.(float32))
-		case int:
-			value = fmt.Sprintf("%d", field.Value.(int))
-		case int8:
-			value = fmt.Sprintf("%d", field.Value.(int8))
-		case int16:
-			value = fmt.Sprintf("%d", field.Value.(int16))
-		case int32:
-			value = fmt.Sprintf("%d", field.Value.(int32))
-		case int64:
-			value = fmt.Sprintf("%d", field.Value.(int64))
-		}
-		otherParams.Add(field.ModelField.Id, value)
-	}
-
-	page := 0
-
-	for {
-		m.neaktor.apiLimiter.Take()
-
-		httpClient := m.neaktor.httpClient
-
-		httpClient.Headers = requrl.NewHeaders()
-		httpClient.Headers.Add("Authorization", m.neaktor.token)
-
-		httpClient.Params = requrl.NewParams()
-
-		for k, v := range otherParams.Values() {
-			for _, e := range v {
-				httpClient.Params.Add(k, e)
-			}
-		}
-
-		httpClient.Params.Add("model_id", m.id)
-		httpClient.Params.Add("status_id", status.Id)
-		httpClient.Params.Add("size", "50")
-		httpClient.Params.Add("page", strconv.Itoa(page))
-
-		response, err := requests.Get(mustUrlJoinPath(ApiGateway, "tasks"), &httpClient)
-		if err != nil {
-			return tasks, fmt.Errorf("/v1/tasks?model_id=%s&status_id=%s&%s&size=%d request error: %w", m.id, status.Id, otherParams.Encode(), page, err)
-		}
-
-		if response.StatusCode >= 500 {
-			m.neaktor.log.Debugf("response status code: %d", response.StatusCode)
-			return tasks, fmt.Errorf("service unavailable, code: %d", response.StatusCode)
-		}
-
-		var tasksResponse TasksResponse
-		if err := json.Unmarshal(response.Content, &tasksResponse); err != nil {
-			m.neaktor.log.Debugf("response code: %d, response body: %v", response.StatusCode, response.Text)
-			return tasks, fmt.Errorf("unmarshaling error: %w", err)
-		}
-		if len(tasksResponse.Code) > 0 {
-			return tasks, parseErrorCode(tasksResponse.Code, tasksResponse.Message)
-		}
-
-		for _, taskData := range tasksResponse.Data {
-			fields := make([]TaskField, 0)
-
-			var startDate time.Time
-			var endDate time.Time
-			var statusClosedDate time.Time
-
-			for _, field := range taskData.Fields {
-				if strings.EqualFold(field.Id, "start") && field.Value != nil {
-					startDate, err = time.Parse("02-01-2006T15:04:05", field.Value.(string))
-					if err != nil {
-						return tasks, fmt.Errorf("task start parse error: %w", err)
-					}
-				}
-				if strings.EqualFold(field.Id, "end") && field.Value != nil {
-					endDate, err = time.Parse("02-01-2006T15:04:05", field.Value.(string))
-					if err != nil {
-						return tasks, fmt.Errorf("task end parse error: %w", err)
-					}
-				}
-				if strings.EqualFold(field.Id, "statusClosedDate") && field.Value != nil {
-					statusClosedDate, err = time.Parse("02-01-2006T15:04:05", field.Value.(string))
-					if err != nil {
-						return tasks, fmt.Errorf("task status closed parse error: %w", err)
-					}
-				}
-
-				fields = append(fields, TaskField{
-					ModelField: m.fields[field.Id],
-					Value:      field.Value,
-					State:      field.State,
-				})
-			}
-
-			var modelStatus ModelStatus
-
-			for _, status := range m.statuses {
-				if strings.EqualFold(status.Name, taskData.Status) {
-					modelStatus = status
-				}
-			}
-
-			tasks = append(tasks, NewTask(m, modelStatus, taskData.Id, taskData.Idx, startDate, endDate, statusClosedDate, fields))
-		}
-
-		if tasksResponse.Total < 50 {
-			break
-		}
-
-		if float64(page) >= math.Ceil(float64(tasksResponse.Total/50)) {
-			break
-		}
+	return m.GetTasksByStatusAndFieldsWithContext(context.Background(), status, fields)
+}
 
-		page++
-	}
+func (m *Model) GetTasksByStatusAndFieldsWithContext(ctx context.Context, status ModelStatus, fields []TaskField) (tasks []ITask, err error) {
+	ctx, span := m.neaktor.startSpan(ctx, "Model.GetTasksByStatusAndFields", m.attribute(), Attribute{Key: AttributeStatusId, Value: status.Id})
+	defer func() { endSpan(span, err) }()
 
-	return tasks, err
+	return collectTasks(m.IterateTasksByStatusAndFieldsWithContext(ctx, status, fields))
 }
 
 func (m *Model) MustGetTasksByStatusAndFields(status ModelStatus, fields []TaskField) (tasks []ITask) {
@@ -889,162 +875,14 @@ func (m *Model) MustGetTasksByStatusAndFields(status ModelStatus, fields []TaskF
 }
 
 func (m *Model) GetTasksByFields(fields []TaskField) (tasks []ITask, err error) {
-	type DataField struct {
-		Id    string      `json:"id"`
-		Value interface{} `json:"value"`
-		State string      `json:"state"`
-	}
-
-	type TasksResponseData struct {
-		Id         int           `json:"id"`
-		ProjectId  string        `json:"projectId"`
-		Fields     []DataField   `json:"fields"`
-		Status     string        `json:"status"`
-		ModelId    string        `json:"modelId"`
-		CanDelete  bool          `json:"canDelete"`
-		ModuleId   string        `json:"moduleId"`
-		Idx        string        `json:"idx"`
-		ParentId   interface{}   `json:"parentId"`
-		SubtaskIds []interface{} `json:"subtaskIds"`
-	}
-
-	type TasksResponseLinks struct {
-		Next string `json:"next"`
-	}
-
-	type TasksResponse struct {
-		NeaktorErrorResponse
-		Data  []TasksResponseData `json:"data"`
-		Links TasksResponseLinks  `json:"links"`
-		Page  int                 `json:"page"`
-		Size  int                 `json:"size"`
-		Total int                 `json:"total"`
-	}
-
-	//
-
-	otherParams := requrl.NewParams()
-	for _, field := range fields {
-		var value string
-		switch field.Value.(type) {
-		case string:
-			value = field.Value.(string)
-		case float64:
-			value = fmt.Sprintf("%f", field.Value.(float64))
-		case float32:
-			value = fmt.Sprintf("%f", field.Value.(float32))
-		case int:
-			value = fmt.Sprintf("%d", field.Value.(int))
-		case int8:
-			value = fmt.Sprintf("%d", field.Value.(int8))
-		case int16:
-			value = fmt.Sprintf("%d", field.Value.(int16))
-		case int32:
-			value = fmt.Sprintf("%d", field.Value.(int32))
-		case int64:
-			value = fmt.Sprintf("%d", field.Value.(int64))
-		}
-		otherParams.Add(field.ModelField.Id, value)
-	}
-
-	page := 0
-
-	for {
-		m.neaktor.apiLimiter.Take()
-
-		httpClient := m.neaktor.httpClient
-
-		httpClient.Headers = requrl.NewHeaders()
-		httpClient.Headers.Add("Authorization", m.neaktor.token)
-
-		httpClient.Params = requrl.NewParams()
-
-		for k, v := range otherParams.Values() {
-			for _, e := range v {
-				httpClient.Params.Add(k, e)
-			}
-		}
-
-		httpClient.Params.Add("model_id", m.id)
-		httpClient.Params.Add("size", "50")
-		httpClient.Params.Add("page", strconv.Itoa(page))
-
-		response, err := requests.Get(mustUrlJoinPath(ApiGateway, "tasks"), &httpClient)
-		if err != nil {
-			return tasks, fmt.Errorf("/v1/tasks?model_id=%s&%s&size=50&page=%d request error: %w", m.id, otherParams.Encode(), page, err)
-		}
-
-		if response.StatusCode >= 500 {
-			m.neaktor.log.Debugf("response status code: %d", response.StatusCode)
-			return tasks, fmt.Errorf("service unavailable, code: %d", response.StatusCode)
-		}
-
-		var tasksResponse TasksResponse
-		if err := json.Unmarshal(response.Content, &tasksResponse); err != nil {
-			m.neaktor.log.Debugf("response code: %d, response body: %v", response.StatusCode, response.Text)
-			return tasks, fmt.Errorf("unmarshaling error: %w", err)
-		}
-		if len(tasksResponse.Code) > 0 {
-			return tasks, parseErrorCode(tasksResponse.Code, tasksResponse.Message)
-		}
-
-		for _, taskData := range tasksResponse.Data {
-			fields := make([]TaskField, 0)
-
-			var startDate time.Time
-			var endDate time.Time
-			var statusClosedDate time.Time
-
-			for _, field := range taskData.Fields {
-				if strings.EqualFold(field.Id, "start") && field.Value != nil {
-					startDate, err = time.Parse("02-01-2006T15:04:05", field.Value.(string))
-					if err != nil {
-						return tasks, fmt.Errorf("task start parse error: %w", err)
-					}
-				}
-				if strings.EqualFold(field.Id, "end") && field.Value != nil {
-					endDate, err = time.Parse("02-01-2006T15:04:05", field.Value.(string))
-					if err != nil {
-						return tasks, fmt.Errorf("task end parse error: %w", err)
-					}
-				}
-				if strings.EqualFold(field.Id, "statusClosedDate") && field.Value != nil {
-					statusClosedDate, err = time.Parse("02-01-2006T15:04:05", field.Value.(string))
-					if err != nil {
-						return tasks, fmt.Errorf("task status closed parse error: %w", err)
-					}
-				}
-
-				fields = append(fields, TaskField{
-					ModelField: m.fields[field.Id],
-					Value:      field.Value,
-					State:      field.State,
-				})
-			}
-
-			var modelStatus ModelStatus
-
-			for _, status := range m.statuses {
-				if strings.EqualFold(status.Name, taskData.Status) {
-					modelStatus = status
-				}
-			}
-
-			tasks = append(tasks, NewTask(m, modelStatus, taskData.Id, taskData.Idx, startDate, endDate, statusClosedDate, fields))
-		}
-
-		if tasksResponse.Total < 50 {
-			break
-		}
-
-		if float64(page) >= math.Ceil(float64(tasksResponse.Total/50)) {
-			break
-		}
+	return m.GetTasksByFieldsWithContext(context.Background(), fields)
+}
 
-		page++
-	}
+func (m *Model) GetTasksByFieldsWithContext(ctx context.Context, fields []TaskField) (tasks []ITask, err error) {
+	ctx, span := m.neaktor.startSpan(ctx, "Model.GetTasksByFields", m.attribute())
+	defer func() { endSpan(span, err) }()
 
-	return tasks, nil
+	return collectTasks(m.IterateTasksByFieldsWithContext(ctx, fields))
 }
 
 func (m *Model) MustGetTasksByFields(fields []TaskField) (tasks []ITask) {
@@ -1058,96 +896,22 @@ func (m *Model) MustGetTasksByFields(fields []TaskField) (tasks []ITask) {
 }
 
 func (m *Model) GetTaskById(id int) (task ITask, err error) {
-	type TaskResponseField struct {
-		Id    string      `json:"id"`
-		Value interface{} `json:"value"`
-		State string      `json:"state"`
-	}
-
-	type TaskResponse struct {
-		Id         int                 `json:"id"`
-		ProjectId  string              `json:"projectId"`
-		Fields     []TaskResponseField `json:"fields"`
-		Status     string              `json:"status"`
-		ModelId    string              `json:"modelId"`
-		CanDelete  bool                `json:"canDelete"`
-		ModuleId   string              `json:"moduleId"`
-		Idx        string              `json:"idx"`
-		ParentId   interface{}         `json:"parentId"`
-		SubtaskIds []interface{}       `json:"subtaskIds"`
-	}
-
-	//
-
-	m.neaktor.apiLimiter.Take()
-
-	httpClient := m.neaktor.httpClient
-
-	httpClient.Headers = requrl.NewHeaders()
-	httpClient.Headers.Add("Authorization", m.neaktor.token)
+	return m.GetTaskByIdWithContext(context.Background(), id)
+}
 
-	response, err := requests.Get(mustUrlJoinPath(ApiGateway, "tasks", strconv.Itoa(id)), &httpClient)
-	if err != nil {
-		return task, fmt.Errorf("/v1/tasks/%d request error: %w", id, err)
-	}
+func (m *Model) GetTaskByIdWithContext(ctx context.Context, id int) (task ITask, err error) {
+	ctx, span := m.neaktor.startSpan(ctx, "Model.GetTaskById", m.attribute(), Attribute{Key: AttributeTaskId, Value: id})
+	defer func() { endSpan(span, err) }()
 
-	if response.StatusCode >= 500 {
-		m.neaktor.log.Debugf("response status code: %d", response.StatusCode)
-		return task, fmt.Errorf("service unavailable, code: %d", response.StatusCode)
-	}
+	request := m.neaktor.newHttpRequest(nethttp.MethodGet, "/v1/tasks/{id}", mustUrlJoinPath(m.neaktor.apiGateway, "tasks", strconv.Itoa(id)))
 
-	var tasksResponse []TaskResponse
-	if err := json.Unmarshal(response.Content, &tasksResponse); err != nil {
-		m.neaktor.log.Debugf("response code: %d, response body: %v", response.StatusCode, response.Text)
-		return task, fmt.Errorf("unmarshaling error: %w", err)
+	var tasksResponse []taskResponse
+	if err := m.neaktor.call(ctx, request, &tasksResponse); err != nil {
+		return task, err
 	}
-	//if len(tasksResponse.Code) > 0 {
-	//	return task, parseErrorCode(tasksResponse.Code, tasksResponse.Message)
-	//}
 
 	for _, taskData := range tasksResponse {
-		fields := make([]TaskField, 0)
-
-		var startDate time.Time
-		var endDate time.Time
-		var statusClosedDate time.Time
-
-		for _, field := range taskData.Fields {
-			if strings.EqualFold(field.Id, "start") && field.Value != nil {
-				startDate, err = time.Parse("02-01-2006T15:04:05", field.Value.(string))
-				if err != nil {
-					return task, fmt.Errorf("task start parse error: %w", err)
-				}
-			}
-			if strings.EqualFold(field.Id, "end") && field.Value != nil {
-				endDate, err = time.Parse("02-01-2006T15:04:05", field.Value.(string))
-				if err != nil {
-					return task, fmt.Errorf("task end parse error: %w", err)
-				}
-			}
-			if strings.EqualFold(field.Id, "statusClosedDate") && field.Value != nil {
-				statusClosedDate, err = time.Parse("02-01-2006T15:04:05", field.Value.(string))
-				if err != nil {
-					return task, fmt.Errorf("task status closed parse error: %w", err)
-				}
-			}
-
-			fields = append(fields, TaskField{
-				ModelField: m.fields[field.Id],
-				Value:      field.Value,
-				State:      field.State,
-			})
-		}
-
-		var modelStatus ModelStatus
-
-		for _, status := range m.statuses {
-			if status.Id == taskData.Status {
-				modelStatus = status
-			}
-		}
-
-		return NewTask(m, modelStatus, taskData.Id, taskData.Idx, startDate, endDate, statusClosedDate, fields), err
+		return m.newTaskFromResponse(taskData)
 	}
 
 	return task, ErrTaskNotFound
@@ -1164,6 +928,13 @@ func (m *Model) MustGetTaskById(id int) (task ITask) {
 }
 
 func (m *Model) CreateTask(assignee ModelAssignee, fields []TaskField) (task ITask, err error) {
+	return m.CreateTaskWithContext(context.Background(), assignee, fields)
+}
+
+func (m *Model) CreateTaskWithContext(ctx context.Context, assignee ModelAssignee, fields []TaskField) (task ITask, err error) {
+	ctx, span := m.neaktor.startSpan(ctx, "Model.CreateTask", m.attribute())
+	defer func() { endSpan(span, err) }()
+
 	type CreateTaskRequestAssignee struct {
 		Id   int    `json:"id,omitempty"`
 		Type string `json:"type,omitempty"`
@@ -1187,7 +958,10 @@ func (m *Model) CreateTask(assignee ModelAssignee, fields []TaskField) (task ITa
 
 	//
 
-	m.neaktor.apiLimiter.Take()
+	fields, err = m.encodeFields(ctx, fields)
+	if err != nil {
+		return task, err
+	}
 
 	createFields := make([]CreateTaskRequestField, 0)
 
@@ -1210,35 +984,18 @@ func (m *Model) CreateTask(assignee ModelAssignee, fields []TaskField) (task ITa
 		return task, fmt.Errorf("marshaling error: %w", err)
 	}
 
-	httpClient := m.neaktor.httpClient
-
-	httpClient.Headers = requrl.NewHeaders()
-	httpClient.Headers.Add("Authorization", m.neaktor.token)
+	request := m.neaktor.newHttpRequest(nethttp.MethodPost, "/v1/tasks/{model_id}", mustUrlJoinPath(m.neaktor.apiGateway, "tasks", m.id))
 
-	httpClient.Body = string(createTaskRequestBytes)
-
-	response, err := requests.Post(mustUrlJoinPath(ApiGateway, "tasks", m.id), &httpClient)
-	if err != nil {
-		return task, fmt.Errorf("/v1/tasks/%s request error: %w", m.id, err)
-	}
-
-	if response.StatusCode >= 500 {
-		m.neaktor.log.Debugf("response status code: %d", response.StatusCode)
-		return task, fmt.Errorf("service unavailable, code: %d", response.StatusCode)
-	}
+	request.Headers.Set("Content-Type", "application/json")
+	request.Body = createTaskRequestBytes
 
 	var createTaskResponse CreateTaskResponse
-	if err := json.Unmarshal(response.Content, &createTaskResponse); err != nil {
-		m.neaktor.log.Debugf("response code: %d, response body: %v", response.StatusCode, response.Text)
-		return task, fmt.Errorf("unmarshaling error: %w", err)
-	}
-	if len(createTaskResponse.Code) > 0 {
-		return task, parseErrorCode(createTaskResponse.Code, createTaskResponse.Message)
+	if err := m.neaktor.call(ctx, request, &createTaskResponse); err != nil {
+		return task, err
 	}
-
 	//
 
-	return m.GetTaskById(createTaskResponse.Id)
+	return m.GetTaskByIdWithContext(ctx, createTaskResponse.Id)
 }
 
 func (m *Model) MustCreateTask(assignee ModelAssignee, fields []TaskField) (task ITask) {
@@ -1250,3 +1007,137 @@ func (m *Model) MustCreateTask(assignee ModelAssignee, fields []TaskField) (task
 
 	return task
 }
+
+func (m *Model) UpdateTaskFields(id int, fields []TaskField) (err error) {
+	return m.UpdateTaskFieldsWithContext(context.Background(), id, fields)
+}
+
+// UpdateTaskFieldsWithContext updates the fields of the task by its id, like ITask.UpdateFields without reading the task
+func (m *Model) UpdateTaskFieldsWithContext(ctx context.Context, id int, fields []TaskField) (err error) {
+	ctx, span := m.neaktor.startSpan(ctx, "Model.UpdateTaskFields", m.attribute(), Attribute{Key: AttributeTaskId, Value: id})
+	defer func() { endSpan(span, err) }()
+
+	return m.updateTaskFields(ctx, id, fields)
+}
+
+func (m *Model) MustUpdateTaskFields(id int, fields []TaskField) {
+	var err error
+	if err = m.UpdateTaskFields(id, fields); err != nil {
+		panic(err)
+	}
+}
+
+//
+
+type taskResponseField struct {
+	Id    string      `json:"id"`
+	Value interface{} `json:"value"`
+	State string      `json:"state"`
+}
+
+// UnmarshalJSON decodes the numbers of the value as json.Number, see decodeFieldValue
+func (f *taskResponseField) UnmarshalJSON(data []byte) (err error) {
+	type TaskResponseField struct {
+		Id    string          `json:"id"`
+		Value json.RawMessage `json:"value"`
+		State string          `json:"state"`
+	}
+
+	var responseField TaskResponseField
+	if err := json.Unmarshal(data, &responseField); err != nil {
+		return err
+	}
+
+	f.Id, f.Value, f.State = responseField.Id, nil, responseField.State
+
+	if len(responseField.Value) > 0 {
+		decoder := json.NewDecoder(bytes.NewReader(responseField.Value))
+		decoder.UseNumber()
+		if err := decoder.Decode(&f.Value); err != nil {
+			return err
+		}
+	}
+
+	return err
+}
+
+type taskResponse struct {
+	Id         int                 `json:"id"`
+	ProjectId  string              `json:"projectId"`
+	Fields     []taskResponseField `json:"fields"`
+	Status     string              `json:"status"`
+	ModelId    string              `json:"modelId"`
+	CanDelete  bool                `json:"canDelete"`
+	ModuleId   string              `json:"moduleId"`
+	Idx        string              `json:"idx"`
+	ParentId   interface{}         `json:"parentId"`
+	SubtaskIds []interface{}       `json:"subtaskIds"`
+}
+
+type tasksResponseLinks struct {
+	Next string `json:"next"`
+}
+
+type tasksResponse struct {
+	NeaktorErrorResponse
+	Data  []taskResponse     `json:"data"`
+	Links tasksResponseLinks `json:"links"`
+	Page  int                `json:"page"`
+	Size  int                `json:"size"`
+	Total int                `json:"total"`
+}
+
+// newTaskFromResponse parses a task of the list and of the single task responses
+func (m *Model) newTaskFromResponse(taskData taskResponse) (task ITask, err error) {
+	fields := make([]TaskField, 0)
+
+	var startDate time.Time
+	var endDate time.Time
+	var statusClosedDate time.Time
+
+	for _, field := range taskData.Fields {
+		if strings.EqualFold(field.Id, "start") && field.Value != nil {
+			startDate, err = time.Parse(taskDateLayout, field.Value.(string))
+			if err != nil {
+				return task, fmt.Errorf("task start parse error: %w", err)
+			}
+		}
+		if strings.EqualFold(field.Id, "end") && field.Value != nil {
+			endDate, err = time.Parse(taskDateLayout, field.Value.(string))
+			if err != nil {
+				return task, fmt.Errorf("task end parse error: %w", err)
+			}
+		}
+		if strings.EqualFold(field.Id, "statusClosedDate") && field.Value != nil {
+			statusClosedDate, err = time.Parse(taskDateLayout, field.Value.(string))
+			if err != nil {
+				return task, fmt.Errorf("task status closed parse error: %w", err)
+			}
+		}
+
+		fields = append(fields, TaskField{
+			ModelField: m.fields[field.Id],
+			Value:      field.Value,
+			State:      field.State,
+		})
+	}
+
+	m.decodeFields(fields)
+
+	return NewTask(m, m.findStatus(taskData.Status), taskData.Id, taskData.Idx, startDate, endDate, statusClosedDate, fields), err
+}
+
+// findStatus matches the status id first, the task list responds with the status name and the single task with the id
+func (m *Model) findStatus(idOrName string) (modelStatus ModelStatus) {
+	if status, present := m.statuses[idOrName]; present {
+		return status
+	}
+
+	for _, status := range m.statuses {
+		if strings.EqualFold(status.Name, idOrName) {
+			return status
+		}
+	}
+
+	return modelStatus
+}
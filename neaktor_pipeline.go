@@ -0,0 +1,153 @@
+package neaktor_api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// RequestHandler sends a request and returns the response, an *APIError is returned together with the rejected response
+type RequestHandler func(ctx context.Context, request *HttpRequest) (response *HttpResponse, err error)
+
+// Middleware wraps every request made by the client, see WithMiddleware
+type Middleware func(next RequestHandler) RequestHandler
+
+// WithMiddleware adds middlewares in front of the built-in ones (auth, retry, rate limit, tracing, metrics, logging, error decoding),
+// the first one passed is the outermost
+func WithMiddleware(middlewares ...Middleware) NeaktorOption {
+	return func(n *Neaktor) {
+		n.middlewares = append(n.middlewares, middlewares...)
+	}
+}
+
+// newPipeline chains the middlewares, the /oauth/token request is made without the auth and rate limit ones
+func (n *Neaktor) newPipeline(isAuthorized bool) RequestHandler {
+	middlewares := make([]Middleware, 0)
+	middlewares = append(middlewares, n.middlewares...)
+	if isAuthorized {
+		middlewares = append(middlewares, n.authMiddleware, n.retryMiddleware, n.rateLimitMiddleware)
+	}
+	middlewares = append(middlewares, n.tracingMiddleware, n.metricsMiddleware, n.loggingMiddleware, n.errorMiddleware)
+
+	handler := RequestHandler(func(ctx context.Context, request *HttpRequest) (response *HttpResponse, err error) {
+		return n.httpDoer.Do(ctx, request)
+	})
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+
+	return handler
+}
+
+// call sends the request through the pipeline and unmarshals the response body into result, if it's not nil
+func (n *Neaktor) call(ctx context.Context, request *HttpRequest, result interface{}) error {
+	return n.callPipeline(ctx, n.pipeline, request, result)
+}
+
+func (n *Neaktor) callPipeline(ctx context.Context, pipeline RequestHandler, request *HttpRequest, result interface{}) error {
+	response, err := pipeline(ctx, request)
+	if err != nil {
+		var apiError *APIError
+		if errors.As(err, &apiError) {
+			return err
+		}
+
+		return fmt.Errorf("%s request error: %w", request.endpoint(), err)
+	}
+
+	if result == nil {
+		return nil
+	}
+
+	if err := json.Unmarshal(response.Body, result); err != nil {
+		n.log.Debugf("response code: %d, response body: %v", response.StatusCode, string(response.Body))
+		return fmt.Errorf("unmarshaling error: %w", err)
+	}
+
+	return nil
+}
+
+func (n *Neaktor) authMiddleware(next RequestHandler) RequestHandler {
+	return func(ctx context.Context, request *HttpRequest) (response *HttpResponse, err error) {
+		tokenSource := n.getTokenSource()
+
+		token, err := tokenSource.Token(ctx)
+		if err != nil {
+			return response, fmt.Errorf("access token error: %w", err)
+		}
+
+		request.Headers.Set("Authorization", token.authorization())
+
+		response, err = next(ctx, request)
+		if !errors.Is(err, ErrCode401) {
+			return response, err
+		}
+
+		// a request rejected with 401 is repeated once with a refreshed token
+		refreshedToken, refreshErr := tokenSource.Refresh(ctx, token)
+		if errors.Is(refreshErr, ErrTokenNotRefreshable) {
+			return response, err
+		}
+		if refreshErr != nil {
+			return response, fmt.Errorf("access token refresh error: %w", refreshErr)
+		}
+
+		n.log.Debugf("%s unauthorized, repeating with a refreshed token", request.endpoint())
+
+		request.Headers.Set("Authorization", refreshedToken.authorization())
+
+		return next(ctx, request)
+	}
+}
+
+func (n *Neaktor) rateLimitMiddleware(next RequestHandler) RequestHandler {
+	return func(ctx context.Context, request *HttpRequest) (response *HttpResponse, err error) {
+		limiterCtx, span := n.startSpan(ctx, "neaktor.rate_limit")
+		err = n.takeApiLimiter(limiterCtx)
+		endSpan(span, err)
+		if err != nil {
+			return response, err
+		}
+
+		return next(ctx, request)
+	}
+}
+
+func (n *Neaktor) loggingMiddleware(next RequestHandler) RequestHandler {
+	return func(ctx context.Context, request *HttpRequest) (response *HttpResponse, err error) {
+		startedAt := time.Now()
+
+		response, err = next(ctx, request)
+
+		if response != nil {
+			n.log.Debugf("%s response status code: %d, took %s", request.endpoint(), response.StatusCode, time.Since(startedAt))
+		} else {
+			n.log.Debugf("%s request error: %v, took %s", request.endpoint(), err, time.Since(startedAt))
+		}
+
+		return response, err
+	}
+}
+
+func (n *Neaktor) errorMiddleware(next RequestHandler) RequestHandler {
+	return func(ctx context.Context, request *HttpRequest) (response *HttpResponse, err error) {
+		response, err = next(ctx, request)
+		if err != nil {
+			return response, err
+		}
+
+		return response, n.checkResponse(request, response)
+	}
+}
+
+// endpoint is the method and the path with the query, used for logging and errors
+func (r *HttpRequest) endpoint() string {
+	url := mustParseUrl(r.Url)
+	if len(r.Params) > 0 {
+		url.RawQuery = r.Params.Encode()
+	}
+
+	return r.Method + " " + url.RequestURI()
+}
@@ -0,0 +1,77 @@
+package neaktor_api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	requrl "github.com/wangluozhe/requests/url"
+)
+
+func TestNeaktorApiMiddleware(t *testing.T) {
+	httpDoer := HttpDoerFunc(func(ctx context.Context, request *HttpRequest) (*HttpResponse, error) {
+		if request.Headers.Get("X-Trace") != "outer,inner" {
+			return &HttpResponse{StatusCode: http.StatusBadRequest, Body: []byte(`{"code":"400","message":"bad trace"}`)}, nil
+		}
+
+		return &HttpResponse{StatusCode: http.StatusNotFound, Body: []byte(`{"code":"404","message":"not found"}`)}, nil
+	})
+
+	var calls []string
+	newMiddleware := func(name string) Middleware {
+		return func(next RequestHandler) RequestHandler {
+			return func(ctx context.Context, request *HttpRequest) (*HttpResponse, error) {
+				if trace := request.Headers.Get("X-Trace"); len(trace) > 0 {
+					request.Headers.Set("X-Trace", trace+","+name)
+				} else {
+					request.Headers.Set("X-Trace", name)
+				}
+
+				response, err := next(ctx, request)
+				if len(request.Headers.Get("Authorization")) <= 0 {
+					t.Errorf("%s: authorization header is expected to be set by the inner auth middleware", name)
+				}
+				calls = append(calls, name+":"+err.Error())
+
+				return response, err
+			}
+		}
+	}
+
+	neaktor := NewNeaktor(*requrl.NewRequest(), "t1o2k3e4n5", 6000, WithHttpDoer(httpDoer), WithMiddleware(newMiddleware("outer"), newMiddleware("inner")))
+
+	_, err := neaktor.GetModelByTitle("Заказ")
+	if !errors.Is(err, ErrCode404) {
+		t.Fatalf("expected ErrCode404, got %v", err)
+	}
+	if len(calls) != 2 || calls[0] != "inner:"+err.Error() || calls[1] != "outer:"+err.Error() {
+		t.Fatalf("unexpected middleware calls: %v", calls)
+	}
+}
+
+func TestNeaktorApiRateLimit(t *testing.T) {
+	n := newNeaktor(*requrl.NewRequest(), 1)
+
+	if err := n.takeApiLimiter(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	cancelledCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := n.takeApiLimiter(cancelledCtx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := n.takeApiLimiter(timeoutCtx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+
+	// a given up wait reserving a slot would leave the limiter a whole slot in debt
+	if tokens := n.apiLimiter.Tokens(); tokens < -0.5 {
+		t.Fatalf("expected the cancelled waits not to use up the slots, got %v tokens", tokens)
+	}
+}
@@ -0,0 +1,547 @@
+package neaktor_api
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	neturl "net/url"
+)
+
+// Task date field ids, they are filtered and sorted like the model fields
+const (
+	TaskFieldStart            = "start"
+	TaskFieldEnd              = "end"
+	TaskFieldStatusClosedDate = "statusClosedDate"
+)
+
+// taskDateLayout is the format of the task dates, in responses and in query params. The dates carry no zone, they are
+// read as UTC and the filter dates are sent in UTC.
+const taskDateLayout = "02-01-2006T15:04:05"
+
+type SortOrder string
+
+const (
+	SortAsc  SortOrder = "asc"
+	SortDesc SortOrder = "desc"
+)
+
+// TaskFilter is an equality filter when IsRange is false, Value is the only value then. A range filter has From and
+// To bounds, a nil bound is open. The values are converted to the Go type of the field type, see FieldType.
+type TaskFilter struct {
+	FieldId string
+	Value   interface{}
+	IsRange bool
+	From    interface{}
+	To      interface{}
+}
+
+type TaskSort struct {
+	FieldId string
+	Order   SortOrder
+}
+
+// TaskQuery selects the tasks of a model, every method modifies the query and returns it for chaining:
+//
+//	tasks, err := model.Query().
+//		Statuses(newStatus, paidStatus).
+//		Where(cityField, "Москва").
+//		WhereRange(amountField, 1000, nil).
+//		WhereStart(time.Now().AddDate(0, -1, 0), time.Time{}).
+//		OrderBy(TaskFieldStart, SortDesc).
+//		Limit(100).
+//		Get()
+//
+// Each status is requested separately, so the tasks are sorted within a status and come status by status. A filter
+// value not matching the field type, e.g. a text for a number field, fails the query with ErrTaskFieldTypeMismatch.
+//
+// The api filters the tasks by the field values only, so the range filters and the sorts are applied by the client to
+// the fetched tasks: a query with range filters fetches all the tasks matching its other filters, a sorted query
+// fetches all the tasks of a status before returning the first one.
+type TaskQuery struct {
+	model    IModel
+	statuses []ModelStatus
+	filters  []TaskFilter
+	sorts    []TaskSort
+	pageSize int
+	limit    int
+	err      error
+}
+
+func NewTaskQuery(model IModel) *TaskQuery {
+	return &TaskQuery{
+		model:    model,
+		pageSize: tasksPageSize,
+	}
+}
+
+func (m *Model) Query() *TaskQuery {
+	return NewTaskQuery(m)
+}
+
+// Statuses adds statuses to the query, the tasks of any of them match, a query without statuses matches all tasks
+func (q *TaskQuery) Statuses(statuses ...ModelStatus) *TaskQuery {
+	q.statuses = append(q.statuses, statuses...)
+	return q
+}
+
+func (q *TaskQuery) Where(field ModelField, value interface{}) *TaskQuery {
+	q.filters = append(q.filters, TaskFilter{FieldId: field.Id, Value: q.encodeValue(field, value)})
+	return q
+}
+
+// WhereFields adds an equality filter for each field value
+func (q *TaskQuery) WhereFields(fields []TaskField) *TaskQuery {
+	for _, field := range fields {
+		q.Where(field.ModelField, field.Value)
+	}
+
+	return q
+}
+
+// WhereRange matches the field values between from and to inclusive, a nil bound is open
+func (q *TaskQuery) WhereRange(field ModelField, from, to interface{}) *TaskQuery {
+	return q.whereRange(field, from, to)
+}
+
+// WhereStart matches the task start date between from and to inclusive, a zero bound is open
+func (q *TaskQuery) WhereStart(from, to time.Time) *TaskQuery {
+	return q.whereDateRange(TaskFieldStart, from, to)
+}
+
+// WhereEnd matches the task end date between from and to inclusive, a zero bound is open
+func (q *TaskQuery) WhereEnd(from, to time.Time) *TaskQuery {
+	return q.whereDateRange(TaskFieldEnd, from, to)
+}
+
+// WhereStatusClosedDate matches the status closed date between from and to inclusive, a zero bound is open
+func (q *TaskQuery) WhereStatusClosedDate(from, to time.Time) *TaskQuery {
+	return q.whereDateRange(TaskFieldStatusClosedDate, from, to)
+}
+
+func (q *TaskQuery) whereDateRange(fieldId string, from, to time.Time) *TaskQuery {
+	var fromBound, toBound interface{}
+	if !from.IsZero() {
+		fromBound = from
+	}
+	if !to.IsZero() {
+		toBound = to
+	}
+
+	return q.whereRange(ModelField{Id: fieldId, Name: fieldId, Type: FieldTypeDate}, fromBound, toBound)
+}
+
+func (q *TaskQuery) whereRange(field ModelField, from, to interface{}) *TaskQuery {
+	q.filters = append(q.filters, TaskFilter{FieldId: field.Id, IsRange: true, From: q.encodeValue(field, from), To: q.encodeValue(field, to)})
+	return q
+}
+
+// OrderBy sorts by the field id, a model field id or one of the TaskField* date ids, the first call is the main order
+func (q *TaskQuery) OrderBy(fieldId string, order SortOrder) *TaskQuery {
+	q.sorts = append(q.sorts, TaskSort{FieldId: fieldId, Order: order})
+	return q
+}
+
+// PageSize sets the tasks requested at once, 50 by default
+func (q *TaskQuery) PageSize(pageSize int) *TaskQuery {
+	q.pageSize = max(1, pageSize)
+	return q
+}
+
+// Limit stops the iteration after limit tasks, the remaining pages of an unsorted query are not requested, 0 is no
+// limit
+func (q *TaskQuery) Limit(limit int) *TaskQuery {
+	q.limit = max(0, limit)
+	return q
+}
+
+func (q *TaskQuery) GetStatuses() []ModelStatus {
+	return q.statuses
+}
+
+func (q *TaskQuery) GetFilters() []TaskFilter {
+	return q.filters
+}
+
+func (q *TaskQuery) GetSorts() []TaskSort {
+	return q.sorts
+}
+
+func (q *TaskQuery) GetPageSize() int {
+	return q.pageSize
+}
+
+func (q *TaskQuery) GetLimit() int {
+	return q.limit
+}
+
+// Err returns the first filter value not matching its field type, the query fails with it when run
+func (q *TaskQuery) Err() error {
+	return q.err
+}
+
+// encodeValue converts the filter value by the field type and keeps the first mismatch as the query error
+func (q *TaskQuery) encodeValue(field ModelField, value interface{}) interface{} {
+	encodedValue, err := encodeQueryValue(field, value)
+	if err != nil && q.err == nil {
+		q.err = err
+	}
+
+	return encodedValue
+}
+
+//
+
+func (q *TaskQuery) Iterate() ITaskIterator {
+	return q.model.IterateTasksWithContext(context.Background(), q)
+}
+
+func (q *TaskQuery) IterateWithContext(ctx context.Context) ITaskIterator {
+	return q.model.IterateTasksWithContext(ctx, q)
+}
+
+func (q *TaskQuery) Scan(scanOptions ScanOptions) ITaskScan {
+	return q.model.ScanTasksWithContext(context.Background(), q, scanOptions)
+}
+
+func (q *TaskQuery) ScanWithContext(ctx context.Context, scanOptions ScanOptions) ITaskScan {
+	return q.model.ScanTasksWithContext(ctx, q, scanOptions)
+}
+
+func (q *TaskQuery) Get() (tasks []ITask, err error) {
+	return q.GetWithContext(context.Background())
+}
+
+func (q *TaskQuery) GetWithContext(ctx context.Context) (tasks []ITask, err error) {
+	return collectTasks(q.model.IterateTasksWithContext(ctx, q))
+}
+
+func (q *TaskQuery) MustGet() (tasks []ITask) {
+	var err error
+	tasks, err = q.Get()
+	if err != nil {
+		panic(err)
+	}
+
+	return tasks
+}
+
+// Count returns the number of the matched tasks, regardless of the limit
+func (q *TaskQuery) Count() (count int, err error) {
+	return q.model.CountTasksWithContext(context.Background(), q)
+}
+
+func (q *TaskQuery) CountWithContext(ctx context.Context) (count int, err error) {
+	return q.model.CountTasksWithContext(ctx, q)
+}
+
+func (q *TaskQuery) Exists() (isExists bool, err error) {
+	return q.ExistsWithContext(context.Background())
+}
+
+func (q *TaskQuery) ExistsWithContext(ctx context.Context) (isExists bool, err error) {
+	count, err := q.model.CountTasksWithContext(ctx, q)
+	if err != nil {
+		return isExists, err
+	}
+
+	return count > 0, err
+}
+
+func (q *TaskQuery) clone() *TaskQuery {
+	clonedQuery := *q
+	clonedQuery.statuses = append([]ModelStatus{}, q.statuses...)
+	clonedQuery.filters = append([]TaskFilter{}, q.filters...)
+	clonedQuery.sorts = append([]TaskSort{}, q.sorts...)
+
+	return &clonedQuery
+}
+
+// values returns the request params for each status, or a single one without status_id for a query without statuses.
+// Only the equality filters are sent, see isMatched and sortTasks for the others.
+func (q *TaskQuery) values() (queries []neturl.Values) {
+	query := neturl.Values{}
+
+	for _, filter := range q.filters {
+		if !filter.IsRange {
+			query.Add(filter.FieldId, formatQueryValue(filter.Value))
+		}
+	}
+
+	if len(q.statuses) <= 0 {
+		return []neturl.Values{query}
+	}
+
+	for _, status := range q.statuses {
+		statusQuery := neturl.Values{}
+		for key, values := range query {
+			statusQuery[key] = append([]string{}, values...)
+		}
+		statusQuery.Set("status_id", status.Id)
+
+		queries = append(queries, statusQuery)
+	}
+
+	return queries
+}
+
+// isRanged reports whether the query has range filters, the api doesn't filter by them
+func (q *TaskQuery) isRanged() bool {
+	for _, filter := range q.filters {
+		if filter.IsRange {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isMatched reports whether the task is within every range filter, a task without the field is out of any range
+func (q *TaskQuery) isMatched(task ITask) bool {
+	for _, filter := range q.filters {
+		if !filter.IsRange {
+			continue
+		}
+
+		value := taskValue(task, filter.FieldId)
+		if value == nil {
+			return false
+		}
+
+		if filter.From != nil {
+			if result, isComparable := compareQueryValues(value, filter.From); !isComparable || result < 0 {
+				return false
+			}
+		}
+		if filter.To != nil {
+			if result, isComparable := compareQueryValues(value, filter.To); !isComparable || result > 0 {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// sortTasks orders the tasks by the sorts in turn, the tasks without the field go last
+func sortTasks(tasks []ITask, sorts []TaskSort) {
+	if len(sorts) <= 0 {
+		return
+	}
+
+	sort.SliceStable(tasks, func(i, j int) bool {
+		for _, taskSort := range sorts {
+			iValue, jValue := taskValue(tasks[i], taskSort.FieldId), taskValue(tasks[j], taskSort.FieldId)
+			if iValue == nil || jValue == nil {
+				if (iValue == nil) != (jValue == nil) {
+					return jValue == nil
+				}
+				continue
+			}
+
+			result, isComparable := compareQueryValues(iValue, jValue)
+			if !isComparable || result == 0 {
+				continue
+			}
+			if taskSort.Order == SortDesc {
+				return result > 0
+			}
+			return result < 0
+		}
+
+		return false
+	})
+}
+
+// taskValue returns the field value or one of the task dates, a zero date is no value
+func taskValue(task ITask, fieldId string) interface{} {
+	var date time.Time
+	switch fieldId {
+	case TaskFieldStart:
+		date = task.GetStartDate()
+	case TaskFieldEnd:
+		date = task.GetEndDate()
+	case TaskFieldStatusClosedDate:
+		date = task.GetStatusClosedDate()
+	default:
+		field, err := task.GetField(ModelField{Id: fieldId})
+		if err != nil {
+			return nil
+		}
+		return field.Value
+	}
+
+	if date.IsZero() {
+		return nil
+	}
+
+	return date
+}
+
+// compareQueryValues compares the dates by time, the texts as is and the numbers and the currency amounts by value
+func compareQueryValues(a, b interface{}) (result int, isComparable bool) {
+	_, isADate := a.(time.Time)
+	_, isBDate := b.(time.Time)
+	if isADate || isBDate {
+		aDate, isADate := toQueryDate(a)
+		bDate, isBDate := toQueryDate(b)
+		return aDate.Compare(bDate), isADate && isBDate
+	}
+
+	if currencyValue, isCurrency := a.(CurrencyFieldValue); isCurrency {
+		a = currencyValue.Value
+	}
+	if currencyValue, isCurrency := b.(CurrencyFieldValue); isCurrency {
+		b = currencyValue.Value
+	}
+
+	aText, isAText := a.(string)
+	bText, isBText := b.(string)
+	if isAText && isBText {
+		return strings.Compare(aText, bText), true
+	}
+
+	aDecimal, isADecimal := toQueryDecimal(a)
+	bDecimal, isBDecimal := toQueryDecimal(b)
+	if isADecimal && isBDecimal {
+		return aDecimal.Cmp(bDecimal), true
+	}
+
+	return result, false
+}
+
+// toQueryDate converts the dates and the date texts of the task date format
+func toQueryDate(value interface{}) (date time.Time, isDate bool) {
+	switch value := value.(type) {
+	case time.Time:
+		return value, true
+	case string:
+		date, err := time.Parse(taskDateLayout, value)
+		return date, err == nil
+	}
+
+	return date, false
+}
+
+// encodeQueryValue converts the filter value to the Go type of the field type: the numbers, the numeric texts and the
+// currency values of the number and currency fields to Decimal, the dates and the date texts of the date fields to
+// time.Time, the users of the user fields to the user id. The values of the fields without a type are kept as is.
+func encodeQueryValue(field ModelField, value interface{}) (encodedValue interface{}, err error) {
+	if value == nil || field.Type == FieldTypeUnknown {
+		return value, err
+	}
+
+	switch field.Type {
+	case FieldTypeText, FieldTypeSelect:
+		if text, isString := value.(string); isString {
+			return text, err
+		}
+	case FieldTypeNumber, FieldTypeCurrency:
+		if currencyValue, isCurrency := value.(CurrencyFieldValue); isCurrency {
+			value = currencyValue.Value
+		}
+		if decimal, isDecimal := toQueryDecimal(value); isDecimal {
+			return decimal, err
+		}
+	case FieldTypeDate:
+		switch value := value.(type) {
+		case time.Time:
+			return value, err
+		case string:
+			if date, err := time.Parse(taskDateLayout, value); err == nil {
+				return date, err
+			}
+		}
+	case FieldTypeUser, FieldTypeTaskLink:
+		if user, isUser := value.(UserRef); isUser && field.Type == FieldTypeUser {
+			return user.Id, err
+		}
+		if id, isId := toQueryId(value); isId {
+			return id, err
+		}
+	}
+
+	return value, fmt.Errorf("%w: %s is %s, got %T %v", ErrTaskFieldTypeMismatch, field.Name, field.Type, value, value)
+}
+
+// toQueryDecimal converts the numbers of any Go number type and the numeric texts to Decimal
+func toQueryDecimal(value interface{}) (decimal Decimal, isDecimal bool) {
+	reflectValue := reflect.ValueOf(value)
+	switch reflectValue.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return NewDecimalFromInt(reflectValue.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		decimal, err := ParseDecimal(strconv.FormatUint(reflectValue.Uint(), 10))
+		return decimal, err == nil
+	case reflect.Float32, reflect.Float64:
+		decimal, err := ParseDecimal(strconv.FormatFloat(reflectValue.Float(), 'f', -1, reflectValue.Type().Bits()))
+		return decimal, err == nil
+	}
+
+	return toDecimal(value)
+}
+
+// toQueryId converts the whole numbers of any Go number type to an id, the texts are no ids
+func toQueryId(value interface{}) (id int, isId bool) {
+	if _, isText := value.(string); isText {
+		return id, false
+	}
+
+	decimal, isDecimal := toQueryDecimal(value)
+	if !isDecimal {
+		return id, false
+	}
+
+	id, err := strconv.Atoi(decimal.String())
+
+	return id, err == nil
+}
+
+// formatQueryValue formats the value as the api expects it, numbers without exponent and trailing zeros, dates in
+// the task date format in UTC
+func formatQueryValue(value interface{}) string {
+	switch value := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	case bool:
+		return strconv.FormatBool(value)
+	case int:
+		return strconv.FormatInt(int64(value), 10)
+	case int8:
+		return strconv.FormatInt(int64(value), 10)
+	case int16:
+		return strconv.FormatInt(int64(value), 10)
+	case int32:
+		return strconv.FormatInt(int64(value), 10)
+	case int64:
+		return strconv.FormatInt(value, 10)
+	case uint:
+		return strconv.FormatUint(uint64(value), 10)
+	case uint8:
+		return strconv.FormatUint(uint64(value), 10)
+	case uint16:
+		return strconv.FormatUint(uint64(value), 10)
+	case uint32:
+		return strconv.FormatUint(uint64(value), 10)
+	case uint64:
+		return strconv.FormatUint(value, 10)
+	case float32:
+		return strconv.FormatFloat(float64(value), 'f', -1, 32)
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	case time.Time:
+		return value.UTC().Format(taskDateLayout)
+	case CurrencyFieldValue:
+		return formatQueryValue(value.Value)
+	case fmt.Stringer:
+		return value.String()
+	}
+
+	return fmt.Sprint(value)
+}
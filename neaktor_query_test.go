@@ -0,0 +1,110 @@
+package neaktor_api
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestNeaktorApiQuery(t *testing.T) {
+	model := NewModel(nil, "m1", map[string]ModelStatus{}, map[string]ModelField{}).(*Model)
+
+	amountField := ModelField{Id: "f_amount"}
+	cityField := ModelField{Id: "f_city"}
+	from := time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)
+
+	queries := model.Query().
+		Statuses(ModelStatus{Id: "s_new"}, ModelStatus{Id: "s_paid"}).
+		Where(cityField, "Москва").
+		WhereRange(amountField, 1.5, nil).
+		WhereStart(from, time.Time{}).
+		OrderBy(TaskFieldStart, SortDesc).
+		values()
+
+	if len(queries) != 2 || queries[0].Get("status_id") != "s_new" || queries[1].Get("status_id") != "s_paid" {
+		t.Fatalf("expected a query per status, got %v", queries)
+	}
+	// the api filters by the field values only, the ranges and the sorts are applied to the fetched tasks
+	if queries[1].Get("f_city") != "Москва" || len(queries[1]) != 2 {
+		t.Errorf("expected only the equality filter and the status sent, got %v", queries[1])
+	}
+
+	for value, expected := range map[interface{}]string{
+		"строка":     "строка",
+		7:            "7",
+		int64(-7):    "-7",
+		float32(0.1): "0.1",
+		1e21:         "1000000000000000000000",
+		true:         "true",
+		from:         "01-03-2024T09:30:00",
+	} {
+		if formatted := formatQueryValue(value); formatted != expected {
+			t.Errorf("expected %v formatted as %q, got %q", value, expected, formatted)
+		}
+	}
+	if formatted := formatQueryValue(from.In(time.FixedZone("MSK", 3*60*60))); formatted != "01-03-2024T09:30:00" {
+		t.Errorf("expected the date sent in UTC, got %q", formatted)
+	}
+
+	if queries := model.Query().values(); len(queries) != 1 || queries[0].Has("status_id") {
+		t.Fatalf("expected a single query without status, got %v", queries)
+	}
+
+	numberField := ModelField{Id: "f_sum", Name: "сумма", Type: FieldTypeNumber}
+	textField := ModelField{Id: "f_city", Name: "город", Type: FieldTypeText}
+	dateField := ModelField{Id: "f_paid", Name: "оплачен", Type: FieldTypeDate}
+
+	query := model.Query().Where(numberField, "1500.10").WhereRange(numberField, int8(1), uint(2)).Where(dateField, "01-03-2024T09:30:00")
+	if err := query.Err(); err != nil {
+		t.Fatal(err)
+	}
+	for key, value := range map[string]string{
+		"f_sum":  "1500.10",
+		"f_paid": "01-03-2024T09:30:00",
+	} {
+		if query.values()[0].Get(key) != value {
+			t.Errorf("expected %s=%s, got %q", key, value, query.values()[0].Get(key))
+		}
+	}
+
+	for _, query := range []*TaskQuery{
+		model.Query().Where(numberField, "много"),
+		model.Query().Where(textField, from),
+		model.Query().WhereRange(dateField, "2024-03-01", nil),
+	} {
+		if _, err := model.queryValues(context.Background(), query); !errors.Is(err, ErrTaskFieldTypeMismatch) {
+			t.Errorf("expected ErrTaskFieldTypeMismatch, got %v", err)
+		}
+	}
+
+	newTask := func(id int, start time.Time, sum interface{}) ITask {
+		return NewTask(model, ModelStatus{}, id, "", start, time.Time{}, time.Time{}, []TaskField{{ModelField: numberField, Value: sum}})
+	}
+	tasks := []ITask{
+		newTask(1, from, MustParseDecimal("1500.10")),
+		newTask(2, time.Time{}, MustParseDecimal("2")),
+		newTask(3, from.AddDate(0, 0, 1), nil),
+		newTask(4, from.AddDate(0, 0, -1), MustParseDecimal("300")),
+	}
+
+	rangeQuery := model.Query().WhereRange(numberField, 2, "1500.1").WhereStart(from.AddDate(0, 0, -1), time.Time{})
+	var matchedIds []int
+	for _, task := range tasks {
+		if rangeQuery.isMatched(task) {
+			matchedIds = append(matchedIds, task.GetId())
+		}
+	}
+	if len(matchedIds) != 2 || matchedIds[0] != 1 || matchedIds[1] != 4 {
+		t.Errorf("expected the tasks 1 and 4 in the ranges, got %v", matchedIds)
+	}
+
+	sortTasks(tasks, []TaskSort{{FieldId: TaskFieldStart, Order: SortDesc}})
+	var sortedIds []int
+	for _, task := range tasks {
+		sortedIds = append(sortedIds, task.GetId())
+	}
+	if len(sortedIds) != 4 || sortedIds[0] != 3 || sortedIds[1] != 1 || sortedIds[2] != 4 || sortedIds[3] != 2 {
+		t.Errorf("expected the tasks sorted by start with the task without it last, got %v", sortedIds)
+	}
+}
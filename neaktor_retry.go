@@ -0,0 +1,139 @@
+package neaktor_api
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+
+	nethttp "net/http"
+)
+
+// RetryPolicy describes how requests answered with 429 or 5xx are repeated.
+// Idempotent requests (GET, PUT) are always retried, POST requests such as CreateTask or AddComment only when
+// RetryNonIdempotent is set, since a lost response could otherwise lead to a duplicate task or comment.
+type RetryPolicy struct {
+	MaxAttempts        int           // attempts including the first one, values below 2 disable retries
+	BaseDelay          time.Duration // delay before the second attempt, doubled for every next one
+	MaxDelay           time.Duration // upper bound of the backoff delay and of the Retry-After delay
+	Jitter             float64       // part of the delay which is randomized, from 0 to 1
+	RetryNonIdempotent bool
+}
+
+var NoRetryPolicy = RetryPolicy{MaxAttempts: 1}
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 4,
+	BaseDelay:   time.Second,
+	MaxDelay:    time.Second * 30,
+	Jitter:      0.2,
+}
+
+func WithRetryPolicy(retryPolicy RetryPolicy) NeaktorOption {
+	return func(n *Neaktor) {
+		n.retryPolicy = retryPolicy
+	}
+}
+
+// retryMiddleware repeats the request according to the retry policy, every attempt goes through the api limiter
+func (n *Neaktor) retryMiddleware(next RequestHandler) RequestHandler {
+	return func(ctx context.Context, request *HttpRequest) (response *HttpResponse, err error) {
+		for attempt := 1; ; attempt++ {
+			response, err = next(ctx, request)
+
+			if attempt >= n.retryPolicy.MaxAttempts || !n.retryPolicy.isRetryable(ctx, request, err) {
+				return response, err
+			}
+
+			delay := n.retryPolicy.delay(attempt, response)
+
+			n.log.Debugf("%s request error: %v, retrying in %s", request.endpoint(), err, delay)
+
+			if err := sleepWithContext(ctx, delay); err != nil {
+				return response, err
+			}
+		}
+	}
+}
+
+// isRetryable accepts transport errors and api errors with 429 or 5xx status, as decoded by the error middleware
+func (p RetryPolicy) isRetryable(ctx context.Context, request *HttpRequest, err error) bool {
+	if err == nil || ctx.Err() != nil {
+		return false
+	}
+
+	if !p.RetryNonIdempotent && !isIdempotentMethod(request.Method) {
+		return false
+	}
+
+	var apiError *APIError
+	if errors.As(err, &apiError) {
+		return errors.Is(err, ErrCode429) || errors.Is(err, ErrCode500)
+	}
+
+	return true
+}
+
+// delay returns the Retry-After delay when the server sends one, else the backoff delay, both bounded by MaxDelay
+func (p RetryPolicy) delay(attempt int, response *HttpResponse) time.Duration {
+	if response != nil {
+		if retryAfter, present := parseRetryAfter(response.Headers.Get("Retry-After")); present {
+			if p.MaxDelay > 0 && retryAfter > p.MaxDelay {
+				return p.MaxDelay
+			}
+			return retryAfter
+		}
+	}
+
+	delay := float64(p.BaseDelay) * math.Pow(2, float64(attempt-1))
+	if p.Jitter > 0 {
+		delay += delay * p.Jitter * (rand.Float64()*2 - 1)
+	}
+
+	// clamped after the jitter, so the randomized delay doesn't exceed MaxDelay either
+	if p.MaxDelay > 0 && delay > float64(p.MaxDelay) {
+		delay = float64(p.MaxDelay)
+	}
+
+	return time.Duration(delay)
+}
+
+func isIdempotentMethod(method string) bool {
+	switch method {
+	case nethttp.MethodGet, nethttp.MethodHead, nethttp.MethodOptions, nethttp.MethodPut, nethttp.MethodDelete:
+		return true
+	}
+
+	return false
+}
+
+func parseRetryAfter(value string) (delay time.Duration, present bool) {
+	value = strings.TrimSpace(value)
+	if len(value) <= 0 {
+		return delay, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := nethttp.ParseTime(value); err == nil {
+		return max(time.Until(date), 0), true
+	}
+
+	return delay, false
+}
+
+func sleepWithContext(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
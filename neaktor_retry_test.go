@@ -0,0 +1,80 @@
+package neaktor_api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	requrl "github.com/wangluozhe/requests/url"
+)
+
+func TestNeaktorApiRetry(t *testing.T) {
+	newFlakyDoer := func(failures int, attempts *int) IHttpDoer {
+		return HttpDoerFunc(func(ctx context.Context, request *HttpRequest) (*HttpResponse, error) {
+			*attempts++
+			if *attempts <= failures {
+				return &HttpResponse{
+					StatusCode: http.StatusTooManyRequests,
+					Headers:    http.Header{"Retry-After": []string{"0"}},
+					Body:       []byte(`{"code":"429 TOO_MANY_REQUESTS","message":"slow down"}`),
+				}, nil
+			}
+
+			return &HttpResponse{
+				StatusCode: http.StatusOK,
+				Body:       []byte(`{"data":[{"id":"m1","name":"Заказ"}],"total":1}`),
+			}, nil
+		})
+	}
+
+	retryPolicy := RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}
+
+	t.Run("RetryGet", func(t *testing.T) {
+		attempts := 0
+		neaktor := NewNeaktor(*requrl.NewRequest(), "t1o2k3e4n5", 6000, WithHttpDoer(newFlakyDoer(2, &attempts)), WithRetryPolicy(retryPolicy))
+
+		if _, err := neaktor.GetModelByTitle("Заказ"); err != nil {
+			t.Fatal(err)
+		}
+		if attempts != 3 {
+			t.Fatalf("expected 3 attempts, got %d", attempts)
+		}
+	})
+
+	t.Run("NoRetryPost", func(t *testing.T) {
+		attempts := 0
+		neaktor := NewNeaktor(*requrl.NewRequest(), "t1o2k3e4n5", 6000, WithHttpDoer(newFlakyDoer(2, &attempts)), WithRetryPolicy(retryPolicy))
+
+		request := neaktor.(*Neaktor).newHttpRequest(http.MethodPost, "/v1/comments/{id}", "https://api.neaktor.com/v1/comments/1")
+
+		err := neaktor.(*Neaktor).call(context.Background(), request, nil)
+		if !errors.Is(err, ErrCode429) {
+			t.Fatalf("expected ErrCode429, got %v", err)
+		}
+		if attempts != 1 {
+			t.Fatalf("expected a single attempt, got %d", attempts)
+		}
+	})
+
+	t.Run("RetryAfter", func(t *testing.T) {
+		delay := DefaultRetryPolicy.delay(1, &HttpResponse{Headers: http.Header{"Retry-After": []string{"7"}}})
+		if delay != time.Second*7 {
+			t.Fatalf("unexpected delay: %s", delay)
+		}
+
+		delay = DefaultRetryPolicy.delay(1, &HttpResponse{Headers: http.Header{"Retry-After": []string{"3600"}}})
+		if delay != DefaultRetryPolicy.MaxDelay {
+			t.Fatalf("expected the delay clamped to %s, got %s", DefaultRetryPolicy.MaxDelay, delay)
+		}
+	})
+
+	t.Run("MaxDelay", func(t *testing.T) {
+		for i := 0; i < 100; i++ {
+			if delay := DefaultRetryPolicy.delay(6, nil); delay > DefaultRetryPolicy.MaxDelay {
+				t.Fatalf("expected the jittered delay bounded by %s, got %s", DefaultRetryPolicy.MaxDelay, delay)
+			}
+		}
+	})
+}
@@ -0,0 +1,283 @@
+package neaktor_api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"time"
+)
+
+var ErrTaskFieldEmpty = errors.New("TASK_FIELD_EMPTY")
+
+// String returns the text of a text field or the option id of a select one, numbers are formatted like in queries
+func (f TaskField) String() (value string, err error) {
+	if err := f.checkType(FieldTypeText, FieldTypeSelect, FieldTypeNumber); err != nil {
+		return value, err
+	}
+
+	switch fieldValue := f.Value.(type) {
+	case nil:
+		return value, f.emptyError()
+	case string:
+		return fieldValue, err
+	case float64, int, Decimal:
+		return formatQueryValue(fieldValue), err
+	}
+
+	return value, f.mismatchError()
+}
+
+// Int returns the value of a number field without a fractional part or the task id of a task link field
+func (f TaskField) Int() (value int, err error) {
+	if err := f.checkType(FieldTypeNumber, FieldTypeTaskLink); err != nil {
+		return value, err
+	}
+
+	switch fieldValue := f.Value.(type) {
+	case nil:
+		return value, f.emptyError()
+	case int:
+		return fieldValue, err
+	case float64:
+		value, isInt := ratInt(new(big.Rat).SetFloat64(fieldValue))
+		if !isInt {
+			return value, f.mismatchError()
+		}
+		return value, err
+	case Decimal:
+		value, isInt := ratInt(fieldValue.Rat())
+		if !isInt {
+			return value, f.mismatchError()
+		}
+		return value, err
+	case string:
+		value, err := strconv.Atoi(fieldValue)
+		if err != nil {
+			return value, f.mismatchError()
+		}
+		return value, err
+	}
+
+	return value, f.mismatchError()
+}
+
+func (f TaskField) Float() (value float64, err error) {
+	if err := f.checkType(FieldTypeNumber); err != nil {
+		return value, err
+	}
+
+	switch fieldValue := f.Value.(type) {
+	case nil:
+		return value, f.emptyError()
+	case float64:
+		return fieldValue, err
+	case int:
+		return float64(fieldValue), err
+	case Decimal:
+		return fieldValue.Float64(), err
+	case string:
+		value, err := strconv.ParseFloat(fieldValue, 64)
+		if err != nil {
+			return value, f.mismatchError()
+		}
+		return value, err
+	}
+
+	return value, f.mismatchError()
+}
+
+// Decimal returns the exact value of a number field, or the amount of a currency field
+func (f TaskField) Decimal() (value Decimal, err error) {
+	if err := f.checkType(FieldTypeNumber, FieldTypeCurrency); err != nil {
+		return value, err
+	}
+
+	switch fieldValue := f.Value.(type) {
+	case nil:
+		return value, f.emptyError()
+	case CurrencyFieldValue:
+		return fieldValue.Value, err
+	}
+
+	value, isDecimal := toDecimal(f.Value)
+	if !isDecimal {
+		return value, f.mismatchError()
+	}
+
+	return value, err
+}
+
+func (f TaskField) Bool() (value bool, err error) {
+	switch fieldValue := f.Value.(type) {
+	case nil:
+		return value, f.emptyError()
+	case bool:
+		return fieldValue, err
+	case string:
+		value, err := strconv.ParseBool(fieldValue)
+		if err != nil {
+			return value, f.mismatchError()
+		}
+		return value, err
+	}
+
+	return value, f.mismatchError()
+}
+
+func (f TaskField) Time() (value time.Time, err error) {
+	if err := f.checkType(FieldTypeDate); err != nil {
+		return value, err
+	}
+
+	switch fieldValue := f.Value.(type) {
+	case nil:
+		return value, f.emptyError()
+	case time.Time:
+		return fieldValue, err
+	case string:
+		value, err := time.Parse(taskDateLayout, fieldValue)
+		if err != nil {
+			return value, f.mismatchError()
+		}
+		return value, err
+	}
+
+	return value, f.mismatchError()
+}
+
+func (f TaskField) Currency() (value CurrencyFieldValue, err error) {
+	if err := f.checkType(FieldTypeCurrency); err != nil {
+		return value, err
+	}
+
+	switch fieldValue := f.Value.(type) {
+	case nil:
+		return value, f.emptyError()
+	case CurrencyFieldValue:
+		return fieldValue, err
+	case map[string]interface{}:
+		amount, isDecimal := toDecimal(fieldValue["value"])
+		currency, isString := fieldValue["currency"].(string)
+		if isDecimal && isString {
+			return CurrencyFieldValue{Value: amount, Currency: currency}, err
+		}
+	}
+
+	return value, f.mismatchError()
+}
+
+// OptionValue returns the option value of a select field read from the api, the options not cached at the read are
+// requested here
+func (f TaskField) OptionValue() (value string, err error) {
+	return f.OptionValueWithContext(context.Background())
+}
+
+func (f TaskField) OptionValueWithContext(ctx context.Context) (value string, err error) {
+	if err := f.checkType(FieldTypeSelect); err != nil {
+		return value, err
+	}
+
+	if f.Value == nil {
+		return value, f.emptyError()
+	}
+	if len(f.Option) > 0 {
+		return f.Option, err
+	}
+
+	optionId, isString := f.Value.(string)
+	if f.model == nil || !isString {
+		return value, fmt.Errorf("%w: %s %v", ErrModelCustomFieldValueNotFound, f.ModelField.Name, f.Value)
+	}
+
+	value, err = f.model.GetCustomFieldValueWithContext(ctx, f.ModelField, optionId)
+	if err != nil {
+		return value, fmt.Errorf("%s option %q error: %w", f.ModelField.Name, optionId, err)
+	}
+
+	return value, err
+}
+
+func (f TaskField) UserRef() (value UserRef, err error) {
+	if err := f.checkType(FieldTypeUser); err != nil {
+		return value, err
+	}
+
+	switch fieldValue := f.Value.(type) {
+	case nil:
+		return value, f.emptyError()
+	case UserRef:
+		return fieldValue, err
+	case float64, map[string]interface{}:
+		decodedValue, err := decodeFieldValue(FieldTypeUser, fieldValue)
+		if err != nil {
+			return value, f.mismatchError()
+		}
+		return decodedValue.(UserRef), err
+	}
+
+	return value, f.mismatchError()
+}
+
+// Strings returns the values of a multi-value field, a single text is a single value
+func (f TaskField) Strings() (values []string, err error) {
+	switch fieldValue := f.Value.(type) {
+	case nil:
+		return values, f.emptyError()
+	case string:
+		return []string{fieldValue}, err
+	case []string:
+		return append(values, fieldValue...), err
+	case []interface{}:
+		for _, item := range fieldValue {
+			value, isString := item.(string)
+			if !isString {
+				return nil, f.mismatchError()
+			}
+
+			values = append(values, value)
+		}
+		return values, err
+	}
+
+	return values, f.mismatchError()
+}
+
+// ratInt converts an integral number within the int range, a nil rat of a non-finite float is no int
+func ratInt(rat *big.Rat) (value int, isInt bool) {
+	if rat == nil || !rat.IsInt() || !rat.Num().IsInt64() {
+		return value, false
+	}
+
+	number := rat.Num().Int64()
+	if number < math.MinInt || number > math.MaxInt {
+		return value, false
+	}
+
+	return int(number), true
+}
+
+// checkType fails when the field type is known and isn't one of the types
+func (f TaskField) checkType(fieldTypes ...FieldType) error {
+	if f.ModelField.Type == FieldTypeUnknown {
+		return nil
+	}
+
+	for _, fieldType := range fieldTypes {
+		if f.ModelField.Type == fieldType {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: %s is %s", ErrTaskFieldTypeMismatch, f.ModelField.Name, f.ModelField.Type)
+}
+
+func (f TaskField) emptyError() error {
+	return fmt.Errorf("%w: %s", ErrTaskFieldEmpty, f.ModelField.Name)
+}
+
+func (f TaskField) mismatchError() error {
+	return fmt.Errorf("%w: %s %T", ErrTaskFieldTypeMismatch, f.ModelField.Name, f.Value)
+}
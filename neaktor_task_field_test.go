@@ -0,0 +1,86 @@
+package neaktor_api
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestNeaktorApiTaskField(t *testing.T) {
+	numberField := ModelField{Name: "сумма", Type: FieldTypeNumber}
+	selectField := ModelField{Name: "доставка", Type: FieldTypeSelect}
+
+	if value, err := (TaskField{ModelField: numberField, Value: float64(1500)}).String(); err != nil || value != "1500" {
+		t.Errorf("expected the number formatted, got %q, %v", value, err)
+	}
+	if value, err := (TaskField{ModelField: numberField, Value: float64(1500)}).Int(); err != nil || value != 1500 {
+		t.Errorf("expected 1500, got %d, %v", value, err)
+	}
+	if _, err := (TaskField{ModelField: numberField, Value: 1.5}).Int(); !errors.Is(err, ErrTaskFieldTypeMismatch) {
+		t.Errorf("expected ErrTaskFieldTypeMismatch for a fractional number, got %v", err)
+	}
+	if value, err := (TaskField{Value: "1.5"}).Float(); err != nil || value != 1.5 {
+		t.Errorf("expected 1.5, got %v, %v", value, err)
+	}
+	if _, err := (TaskField{ModelField: numberField}).Float(); !errors.Is(err, ErrTaskFieldEmpty) {
+		t.Errorf("expected ErrTaskFieldEmpty, got %v", err)
+	}
+	if _, err := (TaskField{ModelField: numberField, Value: float64(1)}).Time(); !errors.Is(err, ErrTaskFieldTypeMismatch) {
+		t.Errorf("expected ErrTaskFieldTypeMismatch by the field type, got %v", err)
+	}
+	if value, err := (TaskField{Value: "true"}).Bool(); err != nil || !value {
+		t.Errorf("expected true, got %t, %v", value, err)
+	}
+	if value, err := (TaskField{Value: "01-03-2024T09:30:00"}).Time(); err != nil || !value.Equal(time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)) {
+		t.Errorf("unexpected time %v, %v", value, err)
+	}
+
+	currencyValue := map[string]interface{}{"value": 99.5, "currency": "RUB"}
+	if value, err := (TaskField{Value: currencyValue}).Currency(); err != nil || value != (CurrencyFieldValue{Value: MustParseDecimal("99.5"), Currency: "RUB"}) {
+		t.Errorf("unexpected currency %v, %v", value, err)
+	}
+	if value, err := (TaskField{ModelField: numberField, Value: MustParseDecimal("0.10")}).Decimal(); err != nil || value.String() != "0.10" {
+		t.Errorf("expected the exact decimal, got %v, %v", value, err)
+	}
+	if value, err := (TaskField{ModelField: numberField, Value: MustParseDecimal("1500")}).Int(); err != nil || value != 1500 {
+		t.Errorf("expected 1500, got %d, %v", value, err)
+	}
+	if value, err := (TaskField{ModelField: numberField, Value: MustParseDecimal("12.0")}).Int(); err != nil || value != 12 {
+		t.Errorf("expected 12 for an integral decimal, got %d, %v", value, err)
+	}
+	if _, err := (TaskField{ModelField: numberField, Value: MustParseDecimal("12.5")}).Int(); !errors.Is(err, ErrTaskFieldTypeMismatch) {
+		t.Errorf("expected ErrTaskFieldTypeMismatch for a fractional decimal, got %v", err)
+	}
+	if _, err := (TaskField{ModelField: numberField, Value: MustParseDecimal("1e30")}).Int(); !errors.Is(err, ErrTaskFieldTypeMismatch) {
+		t.Errorf("expected ErrTaskFieldTypeMismatch for a decimal out of the int range, got %v", err)
+	}
+	if _, err := (TaskField{ModelField: numberField, Value: 1e30}).Int(); !errors.Is(err, ErrTaskFieldTypeMismatch) {
+		t.Errorf("expected ErrTaskFieldTypeMismatch for a number out of the int range, got %v", err)
+	}
+	if _, err := (TaskField{ModelField: numberField, Value: math.Inf(1)}).Int(); !errors.Is(err, ErrTaskFieldTypeMismatch) {
+		t.Errorf("expected ErrTaskFieldTypeMismatch for an infinite number, got %v", err)
+	}
+
+	if value, err := (TaskField{ModelField: selectField, Value: "o1", Option: "курьер"}).OptionValue(); err != nil || value != "курьер" {
+		t.Errorf("expected the option value, got %q, %v", value, err)
+	}
+	if _, err := (TaskField{ModelField: selectField, Value: "o2"}).OptionValue(); !errors.Is(err, ErrModelCustomFieldValueNotFound) {
+		t.Errorf("expected ErrModelCustomFieldValueNotFound, got %v", err)
+	}
+	if _, err := (TaskField{ModelField: selectField, Value: "o2"}).OptionValueWithContext(context.Background()); !errors.Is(err, ErrModelCustomFieldValueNotFound) {
+		t.Errorf("expected ErrModelCustomFieldValueNotFound, got %v", err)
+	}
+
+	if value, err := (TaskField{Value: map[string]interface{}{"id": float64(7), "name": "Менеджер"}}).UserRef(); err != nil || value.Id != 7 {
+		t.Errorf("unexpected user %v, %v", value, err)
+	}
+
+	if values, err := (TaskField{Value: []interface{}{"курьер", "самовывоз"}}).Strings(); err != nil || len(values) != 2 || values[1] != "самовывоз" {
+		t.Errorf("unexpected values %v, %v", values, err)
+	}
+	if _, err := (TaskField{Value: []interface{}{"курьер", 1.0}}).Strings(); !errors.Is(err, ErrTaskFieldTypeMismatch) {
+		t.Errorf("expected ErrTaskFieldTypeMismatch, got %v", err)
+	}
+}
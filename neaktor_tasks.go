@@ -1,24 +1,55 @@
 package neaktor_api
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"github.com/wangluozhe/requests"
-	requrl "github.com/wangluozhe/requests/url"
+	nethttp "net/http"
 	"strconv"
 	"time"
 )
 
+// CurrencyFieldValue is the value of a currency field, the api sends and expects the {"value", "currency"} object. The
+// amount is an exact Decimal, not a float64, see NewCurrencyFieldValue and Float64 for the float amounts.
 type CurrencyFieldValue struct {
-	Value    float64 `json:"value"`
+	Value    Decimal `json:"value"`
 	Currency string  `json:"currency"`
 }
 
+// NewCurrencyFieldValue builds the value from a float amount, NaN and the infinities are invalid, see
+// NewDecimalFromFloat
+func NewCurrencyFieldValue(value float64, currency string) (currencyValue CurrencyFieldValue, err error) {
+	amount, err := NewDecimalFromFloat(value)
+	if err != nil {
+		return currencyValue, err
+	}
+
+	return CurrencyFieldValue{Value: amount, Currency: currency}, err
+}
+
+func MustNewCurrencyFieldValue(value float64, currency string) (currencyValue CurrencyFieldValue) {
+	var err error
+	currencyValue, err = NewCurrencyFieldValue(value, currency)
+	if err != nil {
+		panic(err)
+	}
+
+	return currencyValue
+}
+
+// Float64 returns the amount as float64, it may lose the precision of the Decimal
+func (v CurrencyFieldValue) Float64() float64 {
+	return v.Value.Float64()
+}
+
 type TaskField struct {
 	ModelField ModelField
-	Value      interface{}
+	Value      interface{} // decoded by the field type, see FieldType
 	State      string
+	Option     string // the option value of a select field when its options are cached at the read, the Value is the option id
+
+	model IModel // the model the field is read from, resolves the option value not cached at the read, see OptionValue
 }
 
 type Task struct {
@@ -45,12 +76,16 @@ type ITask interface {
 	GetField(modelField ModelField) (taskField TaskField, err error)
 	MustGetField(modelField ModelField) (taskField TaskField)
 	GetCustomField(modelField ModelField) (taskField TaskField, err error)
+	GetCustomFieldWithContext(ctx context.Context, modelField ModelField) (taskField TaskField, err error)
 	MustGetCustomField(modelField ModelField) (taskField TaskField)
 	UpdateFields(fields []TaskField) error
+	UpdateFieldsWithContext(ctx context.Context, fields []TaskField) error
 	MustUpdateFields(fields []TaskField)
 	UpdateStatus(status ModelStatus) error
+	UpdateStatusWithContext(ctx context.Context, status ModelStatus) error
 	MustUpdateStatus(status ModelStatus)
 	AddComment(message string) error
+	AddCommentWithContext(ctx context.Context, message string) error
 	MustAddComment(message string)
 }
 
@@ -67,6 +102,10 @@ func NewTask(model *Model, status ModelStatus, id int, idx string, startDate, en
 	}
 }
 
+func (t *Task) attribute() Attribute {
+	return Attribute{Key: AttributeTaskId, Value: t.id}
+}
+
 func (t *Task) GetId() int {
 	return t.id
 }
@@ -112,9 +151,26 @@ func (t *Task) MustGetField(modelField ModelField) (taskField TaskField) {
 }
 
 func (t *Task) GetCustomField(modelField ModelField) (taskField TaskField, err error) {
+	return t.GetCustomFieldWithContext(context.Background(), modelField)
+}
+
+func (t *Task) GetCustomFieldWithContext(ctx context.Context, modelField ModelField) (taskField TaskField, err error) {
+	ctx, span := t.model.neaktor.startSpan(ctx, "Task.GetCustomField", t.model.attribute(), t.attribute(), Attribute{Key: AttributeFieldId, Value: modelField.Id})
+	defer func() { endSpan(span, err) }()
+
 	for _, field := range t.fields {
 		if field.ModelField.Id == modelField.Id {
-			value, err := t.model.GetCustomFieldValue(modelField, field.Value.(string))
+			if len(field.Option) > 0 {
+				field.Value = field.Option
+				return field, err
+			}
+
+			optionId, isString := field.Value.(string)
+			if !isString || (field.ModelField.Type != FieldTypeUnknown && field.ModelField.Type != FieldTypeSelect) {
+				return field, ErrTaskFieldTypeMismatch
+			}
+
+			value, err := t.model.GetCustomFieldValueWithContext(ctx, modelField, optionId)
 			if err != nil {
 				return field, err
 			}
@@ -138,6 +194,25 @@ func (t *Task) MustGetCustomField(modelField ModelField) (taskField TaskField) {
 }
 
 func (t *Task) UpdateFields(fields []TaskField) error {
+	return t.UpdateFieldsWithContext(context.Background(), fields)
+}
+
+func (t *Task) UpdateFieldsWithContext(ctx context.Context, fields []TaskField) (err error) {
+	ctx, span := t.model.neaktor.startSpan(ctx, "Task.UpdateFields", t.model.attribute(), t.attribute())
+	defer func() { endSpan(span, err) }()
+
+	return t.model.updateTaskFields(ctx, t.id, fields)
+}
+
+func (t *Task) MustUpdateFields(fields []TaskField) {
+	var err error
+	if err = t.UpdateFields(fields); err != nil {
+		panic(err)
+	}
+}
+
+// updateTaskFields sends the field values of the task, the option values of the select fields are sent as the ids
+func (m *Model) updateTaskFields(ctx context.Context, id int, fields []TaskField) (err error) {
 	type UpdateTaskRequestAssignee struct {
 		Id   int    `json:"id,omitempty"`
 		Type string `json:"type,omitempty"`
@@ -161,7 +236,10 @@ func (t *Task) UpdateFields(fields []TaskField) error {
 
 	//
 
-	t.model.neaktor.apiLimiter.Take()
+	fields, err = m.encodeFields(ctx, fields)
+	if err != nil {
+		return err
+	}
 
 	updateFields := make([]UpdateTaskRequestField, 0)
 
@@ -180,43 +258,26 @@ func (t *Task) UpdateFields(fields []TaskField) error {
 		return fmt.Errorf("marshaling error: %w", err)
 	}
 
-	httpClient := t.model.neaktor.httpClient
-
-	httpClient.Headers = requrl.NewHeaders()
-	httpClient.Headers.Add("Authorization", t.model.neaktor.token)
-
-	httpClient.Body = string(updateTasksRequestBytes)
-
-	response, err := requests.Put(mustUrlJoinPath(ApiGateway, "tasks", strconv.Itoa(t.id)), &httpClient)
-	if err != nil {
-		return fmt.Errorf("/v1/tasks/%d request error: %w", t.id, err)
-	}
+	request := m.neaktor.newHttpRequest(nethttp.MethodPut, "/v1/tasks/{id}", mustUrlJoinPath(m.neaktor.apiGateway, "tasks", strconv.Itoa(id)))
 
-	if response.StatusCode >= 500 {
-		t.model.neaktor.log.Debugf("response status code: %d", response.StatusCode)
-		return fmt.Errorf("service unavailable, code: %d", response.StatusCode)
-	}
+	request.Headers.Set("Content-Type", "application/json")
+	request.Body = updateTasksRequestBytes
 
 	var updateTasksResponse UpdateTasksResponse
-	if err := json.Unmarshal(response.Content, &updateTasksResponse); err != nil {
-		t.model.neaktor.log.Debugf("response code: %d, response body: %v", response.StatusCode, response.Text)
-		return fmt.Errorf("unmarshaling error: %w", err)
-	}
-	if len(updateTasksResponse.Code) > 0 {
-		return parseErrorCode(updateTasksResponse.Code, updateTasksResponse.Message)
+	if err := m.neaktor.call(ctx, request, &updateTasksResponse); err != nil {
+		return err
 	}
-
 	return err
 }
 
-func (t *Task) MustUpdateFields(fields []TaskField) {
-	var err error
-	if err = t.UpdateFields(fields); err != nil {
-		panic(err)
-	}
+func (t *Task) UpdateStatus(status ModelStatus) error {
+	return t.UpdateStatusWithContext(context.Background(), status)
 }
 
-func (t *Task) UpdateStatus(status ModelStatus) error {
+func (t *Task) UpdateStatusWithContext(ctx context.Context, status ModelStatus) (err error) {
+	ctx, span := t.model.neaktor.startSpan(ctx, "Task.UpdateStatus", t.model.attribute(), t.attribute(), Attribute{Key: AttributeStatusId, Value: status.Id})
+	defer func() { endSpan(span, err) }()
+
 	type UpdateTaskStatusRequestAssignee struct {
 		Id   int    `json:"id,omitempty"`
 		Type string `json:"type,omitempty"`
@@ -234,8 +295,6 @@ func (t *Task) UpdateStatus(status ModelStatus) error {
 
 	//
 
-	t.model.neaktor.apiLimiter.Take()
-
 	updateTaskStatusRequest := UpdateTaskStatusRequest{
 		Status: status.Id,
 	}
@@ -244,32 +303,15 @@ func (t *Task) UpdateStatus(status ModelStatus) error {
 		return fmt.Errorf("marshaling error: %w", err)
 	}
 
-	httpClient := t.model.neaktor.httpClient
-
-	httpClient.Headers = requrl.NewHeaders()
-	httpClient.Headers.Add("Authorization", t.model.neaktor.token)
+	request := t.model.neaktor.newHttpRequest(nethttp.MethodPost, "/v1/tasks/{id}/status/change", mustUrlJoinPath(t.model.neaktor.apiGateway, "tasks", strconv.Itoa(t.id), "status", "change"))
 
-	httpClient.Body = string(updateTaskStatusRequestBytes)
-
-	response, err := requests.Post(mustUrlJoinPath(ApiGateway, "tasks", strconv.Itoa(t.id), "status", "change"), &httpClient)
-	if err != nil {
-		return fmt.Errorf("/v1/tasks/%d/status/change request error: %w", t.id, err)
-	}
-
-	if response.StatusCode >= 500 {
-		t.model.neaktor.log.Debugf("response status code: %d", response.StatusCode)
-		return fmt.Errorf("service unavailable, code: %d", response.StatusCode)
-	}
+	request.Headers.Set("Content-Type", "application/json")
+	request.Body = updateTaskStatusRequestBytes
 
 	var updateTaskStatusResponse UpdateTaskStatusResponse
-	if err := json.Unmarshal(response.Content, &updateTaskStatusResponse); err != nil {
-		t.model.neaktor.log.Debugf("response code: %d, response body: %v", response.StatusCode, response.Text)
-		return fmt.Errorf("unmarshaling error: %w", err)
+	if err := t.model.neaktor.call(ctx, request, &updateTaskStatusResponse); err != nil {
+		return err
 	}
-	if len(updateTaskStatusResponse.Code) > 0 {
-		return parseErrorCode(updateTaskStatusResponse.Code, updateTaskStatusResponse.Message)
-	}
-
 	return err
 }
 
@@ -281,6 +323,13 @@ func (t *Task) MustUpdateStatus(status ModelStatus) {
 }
 
 func (t *Task) AddComment(message string) error {
+	return t.AddCommentWithContext(context.Background(), message)
+}
+
+func (t *Task) AddCommentWithContext(ctx context.Context, message string) (err error) {
+	ctx, span := t.model.neaktor.startSpan(ctx, "Task.AddComment", t.model.attribute(), t.attribute())
+	defer func() { endSpan(span, err) }()
+
 	type CreateCommentToTaskRequest struct {
 		Text string `json:"text"`
 	}
@@ -299,32 +348,15 @@ func (t *Task) AddComment(message string) error {
 		return fmt.Errorf("marshaling error: %w", err)
 	}
 
-	httpClient := t.model.neaktor.httpClient
-
-	httpClient.Headers = requrl.NewHeaders()
-	httpClient.Headers.Add("Authorization", t.model.neaktor.token)
-
-	httpClient.Body = string(createCommentToTaskRequestBytes)
+	request := t.model.neaktor.newHttpRequest(nethttp.MethodPost, "/v1/comments/{id}", mustUrlJoinPath(t.model.neaktor.apiGateway, "comments", strconv.Itoa(t.id)))
 
-	response, err := requests.Post(mustUrlJoinPath(ApiGateway, "comments", strconv.Itoa(t.id)), &httpClient)
-	if err != nil {
-		return fmt.Errorf("/v1/comments/%d request error: %w", t.id, err)
-	}
-
-	if response.StatusCode >= 500 {
-		t.model.neaktor.log.Debugf("response status code: %d", response.StatusCode)
-		return fmt.Errorf("service unavailable, code: %d", response.StatusCode)
-	}
+	request.Headers.Set("Content-Type", "application/json")
+	request.Body = createCommentToTaskRequestBytes
 
 	var createCommentToTaskResponse CreateCommentToTaskResponse
-	if err := json.Unmarshal(response.Content, &createCommentToTaskResponse); err != nil {
-		t.model.neaktor.log.Debugf("response code: %d, response body: %v", response.StatusCode, response.Text)
-		return fmt.Errorf("unmarshaling error: %w", err)
+	if err := t.model.neaktor.call(ctx, request, &createCommentToTaskResponse); err != nil {
+		return err
 	}
-	if len(createCommentToTaskResponse.Code) > 0 {
-		return parseErrorCode(createCommentToTaskResponse.Code, createCommentToTaskResponse.Message)
-	}
-
 	return err
 }
 
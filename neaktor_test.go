@@ -1,16 +1,57 @@
 package neaktor_api
 
 import (
+	"context"
+	"errors"
 	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
 	requrl "github.com/wangluozhe/requests/url"
 )
 
+// newCassetteNeaktor replays testdata/cassettes/<test name>.json, with NEAKTOR_CASSETTE_RECORD=1 the cassette is
+// recorded against the real api instead, using the token from NEAKTOR_API_TOKEN
+func newCassetteNeaktor(t *testing.T) (neaktor INeaktor, cassetteDoer *CassetteDoer) {
+	path := filepath.Join("testdata", "cassettes", strings.ReplaceAll(t.Name(), "/", "_")+".json")
+
+	apiToken := "t1o2k3e4n5"
+	apiLimit := 6000
+	mode := CassetteModeReplay
+	if os.Getenv("NEAKTOR_CASSETTE_RECORD") == "1" {
+		apiToken = os.Getenv("NEAKTOR_API_TOKEN")
+		apiLimit = 100
+		mode = CassetteModeRecord
+	}
+
+	cassetteDoer, err := NewCassetteDoer(path, mode, NewRequestsDoer(*requrl.NewRequest()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cassetteDoer.IsSynthetic() {
+		t.Logf("%s is synthetic, it isn't recorded against the real api", path)
+	}
+
+	t.Cleanup(func() {
+		if err := cassetteDoer.Save(); err != nil {
+			t.Error(err)
+		}
+		if mode == CassetteModeReplay && !cassetteDoer.IsReplayed() {
+			t.Error("not every recorded interaction was replayed")
+		}
+	})
+
+	return NewNeaktor(*requrl.NewRequest(), apiToken, apiLimit, WithHttpDoer(cassetteDoer)), cassetteDoer
+}
+
 func TestNeaktorApi(t *testing.T) {
 	t.Run("NeaktorApi", func(t *testing.T) {
-		neaktor := NewNeaktor(*requrl.NewRequest(), "t1o2k3e4n5", 100)
+		neaktor, cassetteDoer := newCassetteNeaktor(t)
 
 		model, err := neaktor.GetModelByTitle("Заказ")
 		if err != nil {
@@ -35,6 +76,8 @@ func TestNeaktorApi(t *testing.T) {
 			t.Fatal(err)
 		}
 
+		cassetteDoer.RedactFields(passwordModelField.Id)
+
 		tasks, err := model.GetTasksByStatus(searchModelStatus)
 		if err != nil {
 			t.Fatal(err)
@@ -48,7 +91,7 @@ func TestNeaktorApi(t *testing.T) {
 
 			emailTaskField, err := task.GetField(emailModelField)
 			if err != nil {
-				panic(err)
+				t.Fatal(err)
 			}
 			emailTaskField.Value = "admin@gmail.com"
 
@@ -56,23 +99,42 @@ func TestNeaktorApi(t *testing.T) {
 
 			if strings.EqualFold(emailTaskField.Value.(string), "admin@google.com") {
 				log.Printf("updating fields")
-				task.UpdateFields([]TaskField{passwordTaskField, emailTaskField})
+				if err := task.UpdateFields([]TaskField{passwordTaskField, emailTaskField}); err != nil {
+					t.Fatal(err)
+				}
 
 				//
 
 				log.Printf("updating status")
-				task.UpdateStatus(newModelStatus)
+				if err := task.UpdateStatus(newModelStatus); err != nil {
+					t.Fatal(err)
+				}
 
 				//
 
 				log.Printf("adding comment")
-				task.AddComment("emailTaskField value fixed")
+				if err := task.AddComment("emailTaskField value fixed"); err != nil {
+					t.Fatal(err)
+				}
 			}
 		}
+
+		assignee, err := model.GetAssignee(searchModelStatus, "Менеджер")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		task, err := model.CreateTask(assignee, []TaskField{{ModelField: emailModelField, Value: "new@gmail.com"}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if task.GetStatus().Id != searchModelStatus.Id {
+			t.Fatalf("unexpected created task status: %v", task.GetStatus())
+		}
 	})
 
 	t.Run("MustNeaktorApi", func(t *testing.T) {
-		neaktor := NewNeaktor(*requrl.NewRequest(), "t1o2k3e4n5", 100)
+		neaktor, cassetteDoer := newCassetteNeaktor(t)
 
 		model := neaktor.MustGetModelByTitle("Заказ")
 
@@ -82,6 +144,8 @@ func TestNeaktorApi(t *testing.T) {
 		emailModelField := model.MustGetField("email")
 		passwordModelField := model.MustGetField("пароль")
 
+		cassetteDoer.RedactFields(passwordModelField.Id)
+
 		tasks := model.MustGetTasksByStatus(searchModelStatus)
 
 		for _, task := range tasks {
@@ -110,5 +174,83 @@ func TestNeaktorApi(t *testing.T) {
 				task.MustAddComment("emailTaskField value fixed")
 			}
 		}
+
+		assignee := model.MustGetAssignee(searchModelStatus, "Менеджер")
+
+		model.MustCreateTask(assignee, []TaskField{{ModelField: emailModelField, Value: "new@gmail.com"}})
+	})
+}
+
+func TestNeaktorApiContext(t *testing.T) {
+	t.Run("CanceledContext", func(t *testing.T) {
+		neaktor := NewNeaktor(*requrl.NewRequest(), "t1o2k3e4n5", 100)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		if _, err := neaktor.GetModelByTitleWithContext(ctx, "Заказ"); !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected context.Canceled, got: %v", err)
+		}
+	})
+}
+
+func TestNeaktorApiBaseUrl(t *testing.T) {
+	t.Run("BaseUrl", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/v1/taskmodels" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+
+			w.Write([]byte(`{"data":[{"id":"m1","name":"Заказ","fields":[],"statuses":[]}],"page":0,"size":100,"total":1}`))
+		}))
+		defer server.Close()
+
+		neaktor := NewNeaktor(*requrl.NewRequest(), "t1o2k3e4n5", 100, WithBaseUrl(server.URL))
+
+		model, err := neaktor.GetModelByTitle("Заказ")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if model.GetId() != "m1" {
+			t.Fatalf("unexpected model id: %q", model.GetId())
+		}
+	})
+}
+
+func TestNeaktorApiHttpDoer(t *testing.T) {
+	t.Run("HttpDoerFunc", func(t *testing.T) {
+		httpDoer := HttpDoerFunc(func(ctx context.Context, request *HttpRequest) (*HttpResponse, error) {
+			if request.Headers.Get("Authorization") != "t1o2k3e4n5" {
+				t.Fatalf("unexpected authorization: %q", request.Headers.Get("Authorization"))
+			}
+			if request.Url != "https://api.neaktor.com/v1/taskmodels" || request.Params.Get("size") != "100" {
+				t.Fatalf("unexpected request: %s %v", request.Url, request.Params)
+			}
+
+			return &HttpResponse{
+				StatusCode: http.StatusOK,
+				Body:       []byte(`{"data":[{"id":"m1","name":"Заказ"}],"total":1}`),
+			}, nil
+		})
+
+		neaktor := NewNeaktor(*requrl.NewRequest(), "t1o2k3e4n5", 100, WithHttpDoer(httpDoer))
+
+		if _, err := neaktor.GetModelByTitle("Заказ"); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("NetHttpDoer", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"data":[{"id":"m1","name":"Заказ"}],"total":1}`))
+		}))
+		defer server.Close()
+
+		neaktor := NewNeaktor(*requrl.NewRequest(), "t1o2k3e4n5", 100, WithBaseUrl(server.URL), WithHttpDoer(NewNetHttpDoer(server.Client())))
+
+		if _, err := neaktor.GetModelByTitle("Заказ"); err != nil {
+			t.Fatal(err)
+		}
 	})
 }
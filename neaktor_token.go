@@ -0,0 +1,240 @@
+package neaktor_api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	nethttp "net/http"
+	neturl "net/url"
+)
+
+// TokenExpiryDelta is how long before the expiration an access token is refreshed
+const TokenExpiryDelta = time.Minute
+
+var ErrTokenNotRefreshable = errors.New("TOKEN_NOT_REFRESHABLE")
+
+type Token struct {
+	AccessToken  string    `json:"access_token"`
+	TokenType    string    `json:"token_type"`
+	RefreshToken string    `json:"refresh_token"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+func (t Token) authorization() string {
+	if len(t.TokenType) <= 0 {
+		return t.AccessToken
+	}
+
+	return t.TokenType + " " + t.AccessToken
+}
+
+func (t Token) isValid() bool {
+	if len(t.AccessToken) <= 0 {
+		return false
+	}
+
+	return t.ExpiresAt.IsZero() || time.Now().Add(TokenExpiryDelta).Before(t.ExpiresAt)
+}
+
+// ITokenSource provides the access token for every request.
+// Refresh is called once a request was rejected with 401, it gets the rejected token so concurrent callers
+// don't refresh the same token twice.
+type ITokenSource interface {
+	Token(ctx context.Context) (token Token, err error)
+	Refresh(ctx context.Context, rejected Token) (token Token, err error)
+}
+
+func WithTokenSource(tokenSource ITokenSource) NeaktorOption {
+	return func(n *Neaktor) {
+		n.tokenSource = tokenSource
+	}
+}
+
+// WithClientCredentials lets the client refresh the access token by itself, see NewNeaktorByRefreshToken
+func WithClientCredentials(clientId, clientSecret string) NeaktorOption {
+	return func(n *Neaktor) {
+		n.clientId = clientId
+		n.clientSecret = clientSecret
+	}
+}
+
+type StaticTokenSource struct {
+	token Token
+}
+
+func NewStaticTokenSource(apiToken string) ITokenSource {
+	return &StaticTokenSource{
+		token: Token{AccessToken: apiToken},
+	}
+}
+
+func (s *StaticTokenSource) Token(ctx context.Context) (token Token, err error) {
+	return s.token, err
+}
+
+func (s *StaticTokenSource) Refresh(ctx context.Context, rejected Token) (token Token, err error) {
+	return token, ErrTokenNotRefreshable
+}
+
+type refreshTokenSource struct {
+	neaktor      *Neaktor
+	clientId     string
+	clientSecret string
+	tokenStore   ITokenStore
+
+	tokenLock sync.Mutex
+	token     Token
+}
+
+func newRefreshTokenSource(neaktor *Neaktor, clientId, clientSecret, refreshToken string) *refreshTokenSource {
+	return &refreshTokenSource{
+		neaktor:      neaktor,
+		clientId:     clientId,
+		clientSecret: clientSecret,
+		tokenStore:   neaktor.tokenStore,
+		tokenLock:    sync.Mutex{},
+		token:        Token{RefreshToken: refreshToken},
+	}
+}
+
+func (s *refreshTokenSource) Token(ctx context.Context) (token Token, err error) {
+	s.tokenLock.Lock()
+	defer s.tokenLock.Unlock()
+
+	if s.token.isValid() {
+		return s.token, err
+	}
+
+	return s.refresh(ctx, s.token)
+}
+
+func (s *refreshTokenSource) Refresh(ctx context.Context, rejected Token) (token Token, err error) {
+	s.tokenLock.Lock()
+	defer s.tokenLock.Unlock()
+
+	// somebody has already replaced the rejected token
+	if s.token.AccessToken != rejected.AccessToken && s.token.isValid() {
+		return s.token, err
+	}
+
+	return s.refresh(ctx, rejected)
+}
+
+func (s *refreshTokenSource) refresh(ctx context.Context, rejected Token) (token Token, err error) {
+	if s.tokenStore != nil {
+		if tokenStoreLocker, ok := s.tokenStore.(ITokenStoreLocker); ok {
+			unlock, err := tokenStoreLocker.Lock(ctx)
+			if err != nil {
+				return token, fmt.Errorf("token store lock error: %w", err)
+			}
+			defer unlock()
+		}
+
+		storedToken, err := s.tokenStore.Load(ctx)
+		if err != nil && !errors.Is(err, ErrTokenNotStored) {
+			return token, fmt.Errorf("token store load error: %w", err)
+		}
+
+		if err == nil {
+			// another process sharing the store may have rotated the token already
+			if storedToken.AccessToken != rejected.AccessToken && storedToken.isValid() {
+				s.token = storedToken
+				return s.token, nil
+			}
+
+			if len(storedToken.RefreshToken) > 0 {
+				s.token.RefreshToken = storedToken.RefreshToken
+			}
+		}
+	}
+
+	if len(s.clientId) <= 0 || len(s.clientSecret) <= 0 {
+		return token, fmt.Errorf("%w: client credentials are not set", ErrTokenNotRefreshable)
+	}
+	if len(s.token.RefreshToken) <= 0 {
+		return token, fmt.Errorf("%w: refresh token is not set", ErrTokenNotRefreshable)
+	}
+
+	token, err = s.neaktor.requestToken(ctx, s.clientId, s.clientSecret, s.token.RefreshToken)
+	if err != nil {
+		return token, err
+	}
+
+	s.token = token
+
+	if s.tokenStore != nil {
+		if err := s.tokenStore.Save(ctx, token); err != nil {
+			s.neaktor.log.Warnf("token store save error: %v", err)
+		}
+	}
+
+	return token, nil
+}
+
+// FIXME временная мера из-за бага в самом неакторе, приходится таким образом доставать ключ
+func (n *Neaktor) requestToken(ctx context.Context, clientId, clientSecret, refreshToken string) (token Token, err error) {
+	type OauthTokenResponse struct {
+		NeaktorErrorResponse
+		AccessToken  string `json:"access_token"`
+		TokenType    string `json:"token_type"`
+		RefreshToken string `json:"refresh_token"`
+		ExpiresIn    int    `json:"expires_in"`
+		Scope        string `json:"scope"`
+	}
+
+	data := neturl.Values{}
+	data.Add("grant_type", "refresh_token")
+	data.Add("redirect_uri", "https://redirectUri.com")
+	data.Add("client_id", clientId)
+	data.Add("client_secret", clientSecret)
+	data.Add("refresh_token", refreshToken)
+
+	request := &HttpRequest{
+		Method:   nethttp.MethodPost,
+		Endpoint: "/oauth/token",
+		Url:      mustUrlJoinPath(n.apiServer, "oauth", "token"),
+		Headers:  nethttp.Header{},
+		Body:     []byte(data.Encode()),
+	}
+	request.Headers.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	var oauthTokenResponse OauthTokenResponse
+	if err := n.callPipeline(ctx, n.oauthPipeline, request, &oauthTokenResponse); err != nil {
+		return token, err
+	}
+
+	if len(oauthTokenResponse.AccessToken) <= 0 {
+		return token, ErrApiTokenIncorrect
+	}
+
+	token = Token{
+		AccessToken:  oauthTokenResponse.AccessToken,
+		TokenType:    "Bearer",
+		RefreshToken: oauthTokenResponse.RefreshToken,
+	}
+	if len(token.RefreshToken) <= 0 {
+		token.RefreshToken = refreshToken
+	}
+	if oauthTokenResponse.ExpiresIn > 0 {
+		token.ExpiresAt = time.Now().Add(time.Duration(oauthTokenResponse.ExpiresIn) * time.Second)
+	}
+
+	return token, err
+}
+
+func (n *Neaktor) getTokenSource() ITokenSource {
+	n.tokenLock.RLock()
+	defer n.tokenLock.RUnlock()
+
+	return n.tokenSource
+}
+
+func (n *Neaktor) setTokenSource(tokenSource ITokenSource) {
+	n.tokenLock.Lock()
+	defer n.tokenLock.Unlock()
+
+	n.tokenSource = tokenSource
+}
@@ -0,0 +1,133 @@
+package neaktor_api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// FileTokenStoreLockTimeout is the age after which a lock file left by a crashed process is ignored, a held lock file is
+// touched well within it
+const FileTokenStoreLockTimeout = time.Second * 30
+
+var fileTokenStoreLockTouchInterval = FileTokenStoreLockTimeout / 3
+
+var ErrTokenNotStored = errors.New("TOKEN_NOT_STORED")
+
+// ITokenStore keeps the rotated tokens between restarts, Load returns ErrTokenNotStored if there is nothing saved yet
+type ITokenStore interface {
+	Load(ctx context.Context) (token Token, err error)
+	Save(ctx context.Context, token Token) (err error)
+}
+
+// ITokenStoreLocker is implemented by stores shared between processes, the lock is held from Load to Save of a refresh
+type ITokenStoreLocker interface {
+	Lock(ctx context.Context) (unlock func(), err error)
+}
+
+func WithTokenStore(tokenStore ITokenStore) NeaktorOption {
+	return func(n *Neaktor) {
+		n.tokenStore = tokenStore
+	}
+}
+
+type FileTokenStore struct {
+	path string
+}
+
+func NewFileTokenStore(path string) ITokenStore {
+	return &FileTokenStore{
+		path: path,
+	}
+}
+
+func (s *FileTokenStore) Load(ctx context.Context) (token Token, err error) {
+	content, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return token, ErrTokenNotStored
+	}
+	if err != nil {
+		return token, fmt.Errorf("token file read error: %w", err)
+	}
+
+	if err := json.Unmarshal(content, &token); err != nil {
+		return token, fmt.Errorf("unmarshaling error: %w", err)
+	}
+
+	return token, err
+}
+
+// Save replaces the file atomically, so a concurrent Load never sees a partially written token
+func (s *FileTokenStore) Save(ctx context.Context, token Token) (err error) {
+	content, err := json.MarshalIndent(token, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling error: %w", err)
+	}
+
+	if err := writeFileAtomic(s.path, content); err != nil {
+		return fmt.Errorf("token file write error: %w", err)
+	}
+
+	return err
+}
+
+func (s *FileTokenStore) Lock(ctx context.Context) (unlock func(), err error) {
+	lockPath := s.path + ".lock"
+
+	for {
+		file, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if err == nil {
+			file.Close()
+
+			return holdLockFile(lockPath), nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return unlock, fmt.Errorf("token lock file create error: %w", err)
+		}
+
+		if info, err := os.Stat(lockPath); err == nil && time.Since(info.ModTime()) > FileTokenStoreLockTimeout {
+			os.Remove(lockPath)
+			continue
+		}
+
+		if err := sleepWithContext(ctx, time.Millisecond*50); err != nil {
+			return unlock, err
+		}
+	}
+}
+
+// holdLockFile touches the lock file until unlocked, so a slow refresh isn't taken for a crashed one by other processes
+func holdLockFile(lockPath string) (unlock func()) {
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+
+	go func() {
+		defer close(stopped)
+
+		ticker := time.NewTicker(fileTokenStoreLockTouchInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case now := <-ticker.C:
+				os.Chtimes(lockPath, now, now)
+			}
+		}
+	}()
+
+	var once sync.Once
+
+	return func() {
+		once.Do(func() {
+			close(done)
+			<-stopped
+			os.Remove(lockPath)
+		})
+	}
+}
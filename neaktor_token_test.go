@@ -0,0 +1,160 @@
+package neaktor_api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	requrl "github.com/wangluozhe/requests/url"
+)
+
+func TestNeaktorApiToken(t *testing.T) {
+	t.Run("RefreshOnUnauthorized", func(t *testing.T) {
+		var refreshes atomic.Int32
+		var accessToken atomic.Value
+		accessToken.Store("")
+
+		httpDoer := HttpDoerFunc(func(ctx context.Context, request *HttpRequest) (*HttpResponse, error) {
+			if request.Url == "https://api.neaktor.com/oauth/token" {
+				refresh := refreshes.Add(1)
+				token := "a" + string(rune('0'+refresh))
+				accessToken.Store(token)
+
+				return &HttpResponse{
+					StatusCode: http.StatusOK,
+					Body:       []byte(`{"access_token":"` + token + `","token_type":"bearer","refresh_token":"r` + string(rune('0'+refresh)) + `","expires_in":3600}`),
+				}, nil
+			}
+
+			// the first access token is revoked on the server side
+			if request.Headers.Get("Authorization") != "Bearer "+accessToken.Load().(string) || accessToken.Load().(string) == "a1" {
+				return &HttpResponse{
+					StatusCode: http.StatusUnauthorized,
+					Body:       []byte(`{"error":"invalid_token","error_description":"Access token expired"}`),
+				}, nil
+			}
+
+			return &HttpResponse{
+				StatusCode: http.StatusOK,
+				Body:       []byte(`{"data":[{"id":"m1","name":"Заказ"}],"total":1}`),
+			}, nil
+		})
+
+		neaktor := NewNeaktorByRefreshToken(*requrl.NewRequest(), "r0", 6000, WithHttpDoer(httpDoer), WithClientCredentials("id", "secret"))
+
+		wg := sync.WaitGroup{}
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				if _, err := neaktor.GetModelByTitle("Заказ"); err != nil {
+					t.Error(err)
+				}
+			}()
+		}
+		wg.Wait()
+
+		if refreshes.Load() != 2 {
+			t.Fatalf("expected 2 refreshes, got %d", refreshes.Load())
+		}
+	})
+
+	t.Run("NoClientCredentials", func(t *testing.T) {
+		neaktor := NewNeaktorByRefreshToken(*requrl.NewRequest(), "r0", 6000)
+
+		if _, err := neaktor.GetModelByTitle("Заказ"); err == nil {
+			t.Fatal("expected an error without client credentials")
+		}
+	})
+}
+
+func TestNeaktorApiTokenStore(t *testing.T) {
+	t.Run("FileTokenStore", func(t *testing.T) {
+		tokenStore := NewFileTokenStore(filepath.Join(t.TempDir(), "token.json"))
+
+		if _, err := tokenStore.Load(context.Background()); !errors.Is(err, ErrTokenNotStored) {
+			t.Fatalf("expected ErrTokenNotStored, got: %v", err)
+		}
+
+		var refreshTokens []string
+		httpDoer := HttpDoerFunc(func(ctx context.Context, request *HttpRequest) (*HttpResponse, error) {
+			if request.Url == "https://api.neaktor.com/oauth/token" {
+				values, _ := url.ParseQuery(string(request.Body))
+				refreshTokens = append(refreshTokens, values.Get("refresh_token"))
+
+				return &HttpResponse{
+					StatusCode: http.StatusOK,
+					Body:       []byte(`{"access_token":"a1","token_type":"bearer","refresh_token":"r1","expires_in":3600}`),
+				}, nil
+			}
+
+			return &HttpResponse{
+				StatusCode: http.StatusOK,
+				Body:       []byte(`{"data":[{"id":"m1","name":"Заказ"}],"total":1}`),
+			}, nil
+		})
+
+		for i := 0; i < 2; i++ {
+			// the second client starts with an outdated refresh token and takes the stored one instead
+			neaktor := NewNeaktorByRefreshToken(*requrl.NewRequest(), "r0", 6000, WithHttpDoer(httpDoer), WithClientCredentials("id", "secret"), WithTokenStore(tokenStore))
+
+			if _, err := neaktor.GetModelByTitle("Заказ"); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		if len(refreshTokens) != 1 || refreshTokens[0] != "r0" {
+			t.Fatalf("unexpected refreshes: %v", refreshTokens)
+		}
+
+		token, err := tokenStore.Load(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if token.RefreshToken != "r1" || token.AccessToken != "a1" {
+			t.Fatalf("unexpected stored token: %+v", token)
+		}
+	})
+
+	t.Run("FileTokenStoreLock", func(t *testing.T) {
+		defer func(interval time.Duration) { fileTokenStoreLockTouchInterval = interval }(fileTokenStoreLockTouchInterval)
+		fileTokenStoreLockTouchInterval = time.Millisecond * 10
+
+		path := filepath.Join(t.TempDir(), "token.json")
+		tokenStore := NewFileTokenStore(path).(ITokenStoreLocker)
+
+		unlock, err := tokenStore.Lock(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// the lock looks abandoned, but its owner is still refreshing and keeps touching it
+		outdatedAt := time.Now().Add(-FileTokenStoreLockTimeout * 2)
+		if err := os.Chtimes(path+".lock", outdatedAt, outdatedAt); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(time.Millisecond * 50)
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*200)
+		defer cancel()
+		if _, err := tokenStore.Lock(ctx); !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("expected the held lock to be kept, got: %v", err)
+		}
+
+		unlock()
+
+		unlock, err = tokenStore.Lock(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		unlock()
+	})
+}
@@ -0,0 +1,96 @@
+package neaktor_api
+
+import (
+	"context"
+	"strconv"
+)
+
+const (
+	AttributeModelId    = "neaktor.model_id"
+	AttributeModelTitle = "neaktor.model_title"
+	AttributeTaskId     = "neaktor.task_id"
+	AttributeStatusId   = "neaktor.status_id"
+	AttributeFieldId    = "neaktor.field_id"
+	AttributePage       = "neaktor.page"
+
+	// http attributes follow the OpenTelemetry semantic conventions
+	AttributeHttpMethod     = "http.request.method"
+	AttributeHttpRoute      = "http.route"
+	AttributeHttpStatusCode = "http.response.status_code"
+	AttributeUrlFull        = "url.full"
+)
+
+// Attribute value is a string, bool, int, int64 or float64
+type Attribute struct {
+	Key   string
+	Value interface{}
+}
+
+// ITracer starts spans for Neaktor, Model and Task operations, with child spans for the rate limiter and every http
+// request. The context returned by Start is passed down, so spans of nested operations have the right parent.
+type ITracer interface {
+	Start(ctx context.Context, name string, attributes ...Attribute) (context.Context, ISpan)
+}
+
+type ISpan interface {
+	SetAttributes(attributes ...Attribute)
+	RecordError(err error)
+	End()
+}
+
+func WithTracer(tracer ITracer) NeaktorOption {
+	return func(n *Neaktor) {
+		n.tracer = tracer
+	}
+}
+
+type nopTracer struct{}
+
+func (nopTracer) Start(ctx context.Context, name string, attributes ...Attribute) (context.Context, ISpan) {
+	return ctx, nopSpan{}
+}
+
+type nopSpan struct{}
+
+func (nopSpan) SetAttributes(attributes ...Attribute) {}
+func (nopSpan) RecordError(err error)                 {}
+func (nopSpan) End()                                  {}
+
+// startSpan is used by the operations as
+//
+//	ctx, span := n.startSpan(ctx, "Model.GetTaskById", ...)
+//	defer func() { endSpan(span, err) }()
+func (n *Neaktor) startSpan(ctx context.Context, name string, attributes ...Attribute) (context.Context, ISpan) {
+	return n.tracer.Start(ctx, name, attributes...)
+}
+
+func endSpan(span ISpan, err error) {
+	if err != nil {
+		span.RecordError(err)
+	}
+
+	span.End()
+}
+
+func (n *Neaktor) tracingMiddleware(next RequestHandler) RequestHandler {
+	return func(ctx context.Context, request *HttpRequest) (response *HttpResponse, err error) {
+		attributes := []Attribute{
+			{Key: AttributeHttpMethod, Value: request.Method},
+			{Key: AttributeHttpRoute, Value: request.route()},
+			{Key: AttributeUrlFull, Value: request.Url},
+		}
+		if page, err := strconv.Atoi(request.Params.Get("page")); err == nil {
+			attributes = append(attributes, Attribute{Key: AttributePage, Value: page})
+		}
+
+		ctx, span := n.startSpan(ctx, request.Method+" "+request.route(), attributes...)
+		defer func() { endSpan(span, err) }()
+
+		response, err = next(ctx, request)
+		if response != nil {
+			span.SetAttributes(Attribute{Key: AttributeHttpStatusCode, Value: response.StatusCode})
+		}
+
+		return response, err
+	}
+}
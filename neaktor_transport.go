@@ -0,0 +1,165 @@
+package neaktor_api
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/wangluozhe/requests"
+	"github.com/wangluozhe/requests/models"
+
+	nethttp "net/http"
+	neturl "net/url"
+
+	requrl "github.com/wangluozhe/requests/url"
+)
+
+type HttpRequest struct {
+	Method   string
+	Endpoint string // path template without ids, e.g. /v1/tasks/{id}
+	Url      string
+	Params   neturl.Values
+	Headers  nethttp.Header
+	Body     []byte
+}
+
+type HttpResponse struct {
+	StatusCode int
+	Headers    nethttp.Header
+	Body       []byte
+}
+
+// IHttpDoer is the transport every Neaktor call goes through
+type IHttpDoer interface {
+	Do(ctx context.Context, request *HttpRequest) (response *HttpResponse, err error)
+}
+
+type HttpDoerFunc func(ctx context.Context, request *HttpRequest) (response *HttpResponse, err error)
+
+func (f HttpDoerFunc) Do(ctx context.Context, request *HttpRequest) (response *HttpResponse, err error) {
+	return f(ctx, request)
+}
+
+func WithHttpDoer(httpDoer IHttpDoer) NeaktorOption {
+	return func(n *Neaktor) {
+		n.httpDoer = httpDoer
+	}
+}
+
+// RequestsDoer sends requests with the github.com/wangluozhe/requests client
+type RequestsDoer struct {
+	httpClient requrl.Request
+}
+
+func NewRequestsDoer(httpClient requrl.Request) IHttpDoer {
+	return &RequestsDoer{
+		httpClient: httpClient,
+	}
+}
+
+func (d *RequestsDoer) Do(ctx context.Context, request *HttpRequest) (response *HttpResponse, err error) {
+	httpClient := d.httpClient
+
+	httpClient.Headers = requrl.NewHeaders()
+	for key, values := range request.Headers {
+		for _, value := range values {
+			httpClient.Headers.Add(key, value)
+		}
+	}
+
+	httpClient.Params = nil
+	if len(request.Params) > 0 {
+		httpClient.Params = requrl.NewParams()
+		for key, values := range request.Params {
+			for _, value := range values {
+				httpClient.Params.Add(key, value)
+			}
+		}
+	}
+
+	httpClient.Data = nil
+	httpClient.Body = string(request.Body)
+
+	// the client has no cancellation support, so the request is only abandoned when ctx is done
+	requestsResponse, err := doWithContext(ctx, func() (*models.Response, error) {
+		return requests.Request(request.Method, request.Url, &httpClient)
+	})
+	if err != nil {
+		return response, err
+	}
+
+	response = &HttpResponse{
+		StatusCode: requestsResponse.StatusCode,
+		Headers:    make(nethttp.Header, len(requestsResponse.Headers)),
+		Body:       requestsResponse.Content,
+	}
+	for key, values := range requestsResponse.Headers {
+		response.Headers[key] = values
+	}
+
+	return response, err
+}
+
+// NetHttpDoer sends requests with the standard net/http client
+type NetHttpDoer struct {
+	httpClient *nethttp.Client
+}
+
+func NewNetHttpDoer(httpClient *nethttp.Client) IHttpDoer {
+	if httpClient == nil {
+		httpClient = nethttp.DefaultClient
+	}
+
+	return &NetHttpDoer{
+		httpClient: httpClient,
+	}
+}
+
+func (d *NetHttpDoer) Do(ctx context.Context, request *HttpRequest) (response *HttpResponse, err error) {
+	url, err := neturl.Parse(request.Url)
+	if err != nil {
+		return response, fmt.Errorf("url parse error: %w", err)
+	}
+	if len(request.Params) > 0 {
+		query := url.Query()
+		for key, values := range request.Params {
+			for _, value := range values {
+				query.Add(key, value)
+			}
+		}
+		url.RawQuery = query.Encode()
+	}
+
+	var body io.Reader
+	if request.Body != nil {
+		body = bytes.NewReader(request.Body)
+	}
+
+	httpRequest, err := nethttp.NewRequestWithContext(ctx, request.Method, url.String(), body)
+	if err != nil {
+		return response, err
+	}
+	for key, values := range request.Headers {
+		for _, value := range values {
+			httpRequest.Header.Add(key, value)
+		}
+	}
+
+	httpResponse, err := d.httpClient.Do(httpRequest)
+	if err != nil {
+		return response, err
+	}
+	defer httpResponse.Body.Close()
+
+	content, err := io.ReadAll(httpResponse.Body)
+	if err != nil {
+		return response, fmt.Errorf("response body read error: %w", err)
+	}
+
+	return &HttpResponse{
+		StatusCode: httpResponse.StatusCode,
+		Headers:    httpResponse.Header,
+		Body:       content,
+	}, err
+}
@@ -0,0 +1,317 @@
+package neaktor_api
+
+import (
+	"context"
+)
+
+// TypedModel reads and writes the model tasks as the structs T, bound to the model fields by the neaktor tags, see
+// Marshal and Unmarshal:
+//
+//	orders := NewTypedModel[Order](neaktor.MustGetModelByTitle("Заказ"))
+//	newOrders, err := orders.GetByStatus(newStatus)
+//	id, err := orders.Create(Order{Email: "user@mail.ru", Delivery: "курьер"})
+//	err = orders.Update(id, Order{Delivery: "самовывоз"}) // with omitempty tags only the set fields are updated
+type TypedModel[T any] struct {
+	model IModel
+}
+
+func NewTypedModel[T any](model IModel) *TypedModel[T] {
+	return &TypedModel[T]{
+		model: model,
+	}
+}
+
+func (m *TypedModel[T]) Model() IModel {
+	return m.model
+}
+
+func (m *TypedModel[T]) GetByStatus(status ModelStatus) (values []T, err error) {
+	return m.GetByStatusWithContext(context.Background(), status)
+}
+
+func (m *TypedModel[T]) GetByStatusWithContext(ctx context.Context, status ModelStatus) (values []T, err error) {
+	return m.collect(ctx, m.model.IterateTasksByStatusWithContext(ctx, status))
+}
+
+func (m *TypedModel[T]) MustGetByStatus(status ModelStatus) (values []T) {
+	var err error
+	values, err = m.GetByStatus(status)
+	if err != nil {
+		panic(err)
+	}
+
+	return values
+}
+
+func (m *TypedModel[T]) GetByStatuses(statuses []ModelStatus) (values []T, err error) {
+	return m.GetByStatusesWithContext(context.Background(), statuses)
+}
+
+func (m *TypedModel[T]) GetByStatusesWithContext(ctx context.Context, statuses []ModelStatus) (values []T, err error) {
+	return m.collect(ctx, m.model.IterateTasksByStatusesWithContext(ctx, statuses))
+}
+
+func (m *TypedModel[T]) MustGetByStatuses(statuses []ModelStatus) (values []T) {
+	var err error
+	values, err = m.GetByStatuses(statuses)
+	if err != nil {
+		panic(err)
+	}
+
+	return values
+}
+
+func (m *TypedModel[T]) GetByStatusAndFields(status ModelStatus, fields []TaskField) (values []T, err error) {
+	return m.GetByStatusAndFieldsWithContext(context.Background(), status, fields)
+}
+
+func (m *TypedModel[T]) GetByStatusAndFieldsWithContext(ctx context.Context, status ModelStatus, fields []TaskField) (values []T, err error) {
+	return m.collect(ctx, m.model.IterateTasksByStatusAndFieldsWithContext(ctx, status, fields))
+}
+
+func (m *TypedModel[T]) MustGetByStatusAndFields(status ModelStatus, fields []TaskField) (values []T) {
+	var err error
+	values, err = m.GetByStatusAndFields(status, fields)
+	if err != nil {
+		panic(err)
+	}
+
+	return values
+}
+
+func (m *TypedModel[T]) GetByFields(fields []TaskField) (values []T, err error) {
+	return m.GetByFieldsWithContext(context.Background(), fields)
+}
+
+func (m *TypedModel[T]) GetByFieldsWithContext(ctx context.Context, fields []TaskField) (values []T, err error) {
+	return m.collect(ctx, m.model.IterateTasksByFieldsWithContext(ctx, fields))
+}
+
+func (m *TypedModel[T]) MustGetByFields(fields []TaskField) (values []T) {
+	var err error
+	values, err = m.GetByFields(fields)
+	if err != nil {
+		panic(err)
+	}
+
+	return values
+}
+
+// Get returns the tasks of the query, the query may be built by the model Query method
+func (m *TypedModel[T]) Get(query *TaskQuery) (values []T, err error) {
+	return m.GetWithContext(context.Background(), query)
+}
+
+func (m *TypedModel[T]) GetWithContext(ctx context.Context, query *TaskQuery) (values []T, err error) {
+	return m.collect(ctx, m.model.IterateTasksWithContext(ctx, query))
+}
+
+func (m *TypedModel[T]) MustGet(query *TaskQuery) (values []T) {
+	var err error
+	values, err = m.Get(query)
+	if err != nil {
+		panic(err)
+	}
+
+	return values
+}
+
+func (m *TypedModel[T]) GetById(id int) (value T, err error) {
+	return m.GetByIdWithContext(context.Background(), id)
+}
+
+func (m *TypedModel[T]) GetByIdWithContext(ctx context.Context, id int) (value T, err error) {
+	task, err := m.model.GetTaskByIdWithContext(ctx, id)
+	if err != nil {
+		return value, err
+	}
+
+	err = UnmarshalWithContext(ctx, m.model, task, &value)
+
+	return value, err
+}
+
+func (m *TypedModel[T]) MustGetById(id int) (value T) {
+	var err error
+	value, err = m.GetById(id)
+	if err != nil {
+		panic(err)
+	}
+
+	return value
+}
+
+//
+
+func (m *TypedModel[T]) IterateByStatus(status ModelStatus) *TypedIterator[T] {
+	return m.IterateByStatusWithContext(context.Background(), status)
+}
+
+func (m *TypedModel[T]) IterateByStatusWithContext(ctx context.Context, status ModelStatus) *TypedIterator[T] {
+	return NewTypedIteratorWithContext[T](ctx, m.model, m.model.IterateTasksByStatusWithContext(ctx, status))
+}
+
+func (m *TypedModel[T]) IterateByStatuses(statuses []ModelStatus) *TypedIterator[T] {
+	return m.IterateByStatusesWithContext(context.Background(), statuses)
+}
+
+func (m *TypedModel[T]) IterateByStatusesWithContext(ctx context.Context, statuses []ModelStatus) *TypedIterator[T] {
+	return NewTypedIteratorWithContext[T](ctx, m.model, m.model.IterateTasksByStatusesWithContext(ctx, statuses))
+}
+
+func (m *TypedModel[T]) IterateByStatusAndFields(status ModelStatus, fields []TaskField) *TypedIterator[T] {
+	return m.IterateByStatusAndFieldsWithContext(context.Background(), status, fields)
+}
+
+func (m *TypedModel[T]) IterateByStatusAndFieldsWithContext(ctx context.Context, status ModelStatus, fields []TaskField) *TypedIterator[T] {
+	return NewTypedIteratorWithContext[T](ctx, m.model, m.model.IterateTasksByStatusAndFieldsWithContext(ctx, status, fields))
+}
+
+func (m *TypedModel[T]) IterateByFields(fields []TaskField) *TypedIterator[T] {
+	return m.IterateByFieldsWithContext(context.Background(), fields)
+}
+
+func (m *TypedModel[T]) IterateByFieldsWithContext(ctx context.Context, fields []TaskField) *TypedIterator[T] {
+	return NewTypedIteratorWithContext[T](ctx, m.model, m.model.IterateTasksByFieldsWithContext(ctx, fields))
+}
+
+func (m *TypedModel[T]) Iterate(query *TaskQuery) *TypedIterator[T] {
+	return m.IterateWithContext(context.Background(), query)
+}
+
+func (m *TypedModel[T]) IterateWithContext(ctx context.Context, query *TaskQuery) *TypedIterator[T] {
+	return NewTypedIteratorWithContext[T](ctx, m.model, m.model.IterateTasksWithContext(ctx, query))
+}
+
+//
+
+// Create creates the task without an assignee and returns its id, see CreateAssigned
+func (m *TypedModel[T]) Create(value T) (id int, err error) {
+	return m.CreateAssignedWithContext(context.Background(), ModelAssignee{}, value)
+}
+
+func (m *TypedModel[T]) CreateWithContext(ctx context.Context, value T) (id int, err error) {
+	return m.CreateAssignedWithContext(ctx, ModelAssignee{}, value)
+}
+
+func (m *TypedModel[T]) MustCreate(value T) (id int) {
+	var err error
+	id, err = m.Create(value)
+	if err != nil {
+		panic(err)
+	}
+
+	return id
+}
+
+func (m *TypedModel[T]) CreateAssigned(assignee ModelAssignee, value T) (id int, err error) {
+	return m.CreateAssignedWithContext(context.Background(), assignee, value)
+}
+
+func (m *TypedModel[T]) CreateAssignedWithContext(ctx context.Context, assignee ModelAssignee, value T) (id int, err error) {
+	fields, err := MarshalWithContext(ctx, m.model, value)
+	if err != nil {
+		return id, err
+	}
+
+	task, err := m.model.CreateTaskWithContext(ctx, assignee, fields)
+	if err != nil {
+		return id, err
+	}
+
+	return task.GetId(), err
+}
+
+// Update updates the task fields by the value without reading the task, the omitempty fields left zero aren't updated
+func (m *TypedModel[T]) Update(id int, value T) (err error) {
+	return m.UpdateWithContext(context.Background(), id, value)
+}
+
+func (m *TypedModel[T]) UpdateWithContext(ctx context.Context, id int, value T) (err error) {
+	fields, err := MarshalWithContext(ctx, m.model, value)
+	if err != nil {
+		return err
+	}
+
+	return m.model.UpdateTaskFieldsWithContext(ctx, id, fields)
+}
+
+func (m *TypedModel[T]) MustUpdate(id int, value T) {
+	var err error
+	if err = m.Update(id, value); err != nil {
+		panic(err)
+	}
+}
+
+func (m *TypedModel[T]) collect(ctx context.Context, iterator ITaskIterator) (values []T, err error) {
+	typedIterator := NewTypedIteratorWithContext[T](ctx, m.model, iterator)
+	for typedIterator.Next() {
+		values = append(values, typedIterator.Value())
+	}
+
+	return values, typedIterator.Err()
+}
+
+// TypedIterator unmarshals the tasks of the iterator, the iteration stops at the first unmarshaling error:
+//
+//	iterator := orders.IterateByStatus(newStatus)
+//	for iterator.Next() {
+//		order := iterator.Value()
+//	}
+//	if err := iterator.Err(); err != nil {
+//		return err
+//	}
+type TypedIterator[T any] struct {
+	ctx      context.Context
+	model    IModel
+	iterator ITaskIterator
+	value    T
+	err      error
+}
+
+func NewTypedIterator[T any](model IModel, iterator ITaskIterator) *TypedIterator[T] {
+	return NewTypedIteratorWithContext[T](context.Background(), model, iterator)
+}
+
+// NewTypedIteratorWithContext unmarshals the tasks with the context, the options not cached at the read are requested
+// with it
+func NewTypedIteratorWithContext[T any](ctx context.Context, model IModel, iterator ITaskIterator) *TypedIterator[T] {
+	return &TypedIterator[T]{
+		ctx:      ctx,
+		model:    model,
+		iterator: iterator,
+	}
+}
+
+func (i *TypedIterator[T]) Next() bool {
+	if i.err != nil || !i.iterator.Next() {
+		return false
+	}
+
+	var value T
+	if err := UnmarshalWithContext(i.ctx, i.model, i.iterator.Task(), &value); err != nil {
+		i.err = err
+		return false
+	}
+
+	i.value = value
+
+	return true
+}
+
+func (i *TypedIterator[T]) Value() T {
+	return i.value
+}
+
+// Task returns the task of the current value
+func (i *TypedIterator[T]) Task() ITask {
+	return i.iterator.Task()
+}
+
+func (i *TypedIterator[T]) Err() error {
+	if i.err != nil {
+		return i.err
+	}
+
+	return i.iterator.Err()
+}
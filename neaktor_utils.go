@@ -1,32 +1,17 @@
 package neaktor_api
 
 import (
+	"context"
 	"fmt"
-	"strings"
+	"os"
+	"path/filepath"
+	"time"
+
+	"golang.org/x/time/rate"
 
 	neturl "net/url"
 )
 
-func parseErrorCode(code string, message string) error {
-	if strings.EqualFold(code, ErrCode403.Error()) {
-		return fmt.Errorf("%w: %s", ErrCode403, message)
-	}
-	if strings.EqualFold(code, ErrCode404.Error()) {
-		return fmt.Errorf("%w: %s", ErrCode404, message)
-	}
-	if strings.EqualFold(code, ErrCode429.Error()) {
-		return fmt.Errorf("%w: %s", ErrCode429, message)
-	}
-	if strings.EqualFold(code, ErrCode422.Error()) {
-		return fmt.Errorf("%w: %s", ErrCode422, message)
-	}
-	if strings.EqualFold(code, ErrCode500.Error()) {
-		return fmt.Errorf("%w: %s", ErrCode500, message)
-	}
-
-	return ErrCodeUnknown
-}
-
 func mustUrlJoinPath(base string, path ...string) string {
 	url, err := neturl.JoinPath(base, path...)
 	if err != nil {
@@ -44,3 +29,84 @@ func mustParseUrl(rawUrl string) *neturl.URL {
 
 	return parsedUrl
 }
+
+// doWithContext runs fn in the background and stops waiting for it as soon as ctx is done.
+// It is used for blocking calls which have no cancellation support of their own.
+func doWithContext[T any](ctx context.Context, fn func() (T, error)) (result T, err error) {
+	if err = ctx.Err(); err != nil {
+		return result, err
+	}
+
+	type outcome struct {
+		result T
+		err    error
+	}
+
+	done := make(chan outcome, 1)
+	go func() {
+		result, err := fn()
+		done <- outcome{result: result, err: err}
+	}()
+
+	select {
+	case o := <-done:
+		return o.result, o.err
+	case <-ctx.Done():
+		return result, ctx.Err()
+	}
+}
+
+// newApiLimiter spreads apiLimit requests evenly over a minute, a not positive apiLimit disables the limit
+func newApiLimiter(apiLimit int) *rate.Limiter {
+	if apiLimit <= 0 {
+		return rate.NewLimiter(rate.Inf, 1)
+	}
+
+	return rate.NewLimiter(rate.Every(time.Minute/time.Duration(apiLimit)), 1)
+}
+
+// takeApiLimiter waits for a request slot, a cancelled wait doesn't use up the slot
+func (n *Neaktor) takeApiLimiter(ctx context.Context) (err error) {
+	startedAt := time.Now()
+
+	err = n.apiLimiter.Wait(ctx)
+
+	n.metrics.ObserveRateLimitWait(time.Since(startedAt))
+
+	if err != nil && ctx.Err() == nil {
+		// the limiter refuses to wait past the context deadline without waiting for it
+		return fmt.Errorf("%w: %v", context.DeadlineExceeded, err)
+	}
+	if err != nil {
+		return ctx.Err()
+	}
+
+	return err
+}
+
+// writeFileAtomic writes to a temporary file next to path and renames it, so readers never see a partial content
+func writeFileAtomic(path string, content []byte) (err error) {
+	file, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("temp file create error: %w", err)
+	}
+	defer os.Remove(file.Name())
+
+	if _, err := file.Write(content); err != nil {
+		file.Close()
+		return fmt.Errorf("temp file write error: %w", err)
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return fmt.Errorf("temp file sync error: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("temp file close error: %w", err)
+	}
+
+	if err := os.Rename(file.Name(), path); err != nil {
+		return fmt.Errorf("temp file rename error: %w", err)
+	}
+
+	return err
+}
@@ -0,0 +1,113 @@
+// Package neaktorfake implements INeaktor, IModel and ITask over an in-memory store, for unit tests of code which
+// depends on the interfaces and doesn't need http at all:
+//
+//	neaktor := neaktorfake.NewNeaktor()
+//	model := neaktor.AddModel("Заказ", "m1", statuses, fields)
+//	model.AddTask(newStatus, []neaktor_api.TaskField{...})
+//	neaktor.FailNext("Task.UpdateStatus", neaktor_api.ErrCode500)
+//
+//	runWorker(neaktor)
+//
+//	calls := neaktor.CallsOf("Task.UpdateFields")
+//
+// Calls and errors are keyed by the interface name and the method, without the WithContext and Must prefixes,
+// e.g. "Neaktor.GetModelByTitle", "Model.CreateTask" or "Task.AddComment".
+package neaktorfake
+
+import (
+	"context"
+	"sync"
+)
+
+// Call is a method call received by a fake, Args are the arguments without the context
+type Call struct {
+	Method string
+	Args   []interface{}
+}
+
+// recorder is shared by a fake Neaktor and all its models and tasks, it also guards their state
+type recorder struct {
+	lock       sync.Mutex
+	calls      []Call
+	errors     map[string]error
+	nextErrors map[string][]error
+}
+
+func newRecorder() *recorder {
+	return &recorder{
+		errors:     make(map[string]error, 0),
+		nextErrors: make(map[string][]error, 0),
+	}
+}
+
+// record saves the call and returns the error injected for the method or the context error, the lock must be held
+func (r *recorder) record(ctx context.Context, method string, args ...interface{}) error {
+	r.calls = append(r.calls, Call{
+		Method: method,
+		Args:   args,
+	})
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if nextErrors := r.nextErrors[method]; len(nextErrors) > 0 {
+		r.nextErrors[method] = nextErrors[1:]
+		return nextErrors[0]
+	}
+
+	return r.errors[method]
+}
+
+// SetError makes every call of the method fail with err, a nil err removes it
+func (r *recorder) SetError(method string, err error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if err == nil {
+		delete(r.errors, method)
+		return
+	}
+
+	r.errors[method] = err
+}
+
+// FailNext makes the next call of the method fail with err, errors queued for the same method are returned in order
+func (r *recorder) FailNext(method string, err error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.nextErrors[method] = append(r.nextErrors[method], err)
+}
+
+// Calls returns all calls received by the fake and its models and tasks, in order
+func (r *recorder) Calls() (calls []Call) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return append(calls, r.calls...)
+}
+
+// CallsOf returns the calls of the method, in order
+func (r *recorder) CallsOf(method string) (calls []Call) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	for _, call := range r.calls {
+		if call.Method == method {
+			calls = append(calls, call)
+		}
+	}
+
+	return calls
+}
+
+// Reset forgets the recorded calls and the injected errors, the stored models and tasks are kept
+func (r *recorder) Reset() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.calls = nil
+	r.errors = make(map[string]error, 0)
+	r.nextErrors = make(map[string][]error, 0)
+}
@@ -0,0 +1,193 @@
+package neaktorfake_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	neaktor_api "github.com/tanreon/go-neaktor-api"
+	"github.com/tanreon/go-neaktor-api/neaktorfake"
+)
+
+func newNeaktor() (*neaktorfake.Neaktor, *neaktorfake.Model) {
+	neaktor := neaktorfake.NewNeaktor()
+
+	model := neaktor.AddModel("Заказ", "m1", []neaktor_api.ModelStatus{
+		{Id: "s_new", Name: "новый заказ"},
+		{Id: "s_done", Name: "выполнен"},
+	}, []neaktor_api.ModelField{
+		{Id: "f_email", Name: "email"},
+		{Id: "f_delivery", Name: "доставка"},
+	})
+
+	return neaktor, model
+}
+
+func TestFake(t *testing.T) {
+	t.Run("Tasks", func(t *testing.T) {
+		neaktor, fakeModel := newNeaktor()
+
+		model := neaktor.MustGetModelByTitle("Заказ")
+		newStatus := model.MustGetStatus("новый заказ")
+		doneStatus := model.MustGetStatus("выполнен")
+		emailField := model.MustGetField("email")
+		deliveryField := model.MustGetField("доставка")
+
+		fakeModel.AddTask(newStatus, []neaktor_api.TaskField{{ModelField: emailField, Value: "first@mail.ru"}})
+		fakeModel.AddTask(newStatus, []neaktor_api.TaskField{{ModelField: emailField, Value: "second@mail.ru"}})
+		fakeModel.AddCustomFieldOption(deliveryField, "o1", "курьер")
+
+		tasks := model.MustGetTasksByStatusAndFields(newStatus, []neaktor_api.TaskField{{ModelField: emailField, Value: "second@mail.ru"}})
+		if len(tasks) != 1 || tasks[0].GetId() != 2 {
+			t.Fatalf("expected the task 2, got %d tasks", len(tasks))
+		}
+
+		if tasks := model.Query().Statuses(newStatus).OrderBy(emailField.Id, neaktor_api.SortAsc).Limit(1).MustGet(); len(tasks) != 1 || tasks[0].GetId() != 1 {
+			t.Fatalf("expected the task 1 first by email, got %d tasks", len(tasks))
+		}
+
+		task := tasks[0]
+		task.MustUpdateFields([]neaktor_api.TaskField{{ModelField: deliveryField, Value: "o1"}})
+		task.MustUpdateStatus(doneStatus)
+		task.MustAddComment("доставлен")
+
+		if value := task.MustGetCustomField(deliveryField).Value; value != "курьер" {
+			t.Fatalf("expected the option value, got %v", value)
+		}
+		if tasks := model.Query().Where(deliveryField, "курьер").MustGet(); len(tasks) != 1 || tasks[0].MustGetField(deliveryField).Option != "курьер" {
+			t.Fatalf("expected the task by the option value, got %d tasks", len(tasks))
+		}
+		if err := task.UpdateFields([]neaktor_api.TaskField{{ModelField: deliveryField, Value: "почта"}}); !errors.Is(err, neaktor_api.ErrModelCustomFieldOptionNotFound) {
+			t.Fatalf("expected ErrModelCustomFieldOptionNotFound, got %v", err)
+		}
+		if tasks := model.MustGetTasksByStatus(doneStatus); len(tasks) != 1 {
+			t.Fatalf("expected 1 done task, got %d", len(tasks))
+		}
+		if comments := fakeModel.Tasks()[1].Comments(); len(comments) != 1 || comments[0] != "доставлен" {
+			t.Fatalf("unexpected comments %v", comments)
+		}
+
+		if isExists, err := model.IsTasksByStatusesExists(nil); err != nil || isExists {
+			t.Fatalf("expected no tasks for no statuses, got %t, %v", isExists, err)
+		}
+
+		readTask := model.MustGetTaskById(1)
+		model.MustGetTaskById(1).MustUpdateStatus(doneStatus)
+		if readTask.GetStatus().Id != newStatus.Id || model.MustGetTaskById(1).GetStatus().Id != doneStatus.Id {
+			t.Fatalf("expected the read task to be a copy of the stored one")
+		}
+
+		createdTask := model.MustCreateTask(neaktor_api.NewModelAssignee(7, "Менеджер", "USER"), nil)
+		if createdTask.GetId() != 3 || createdTask.GetStatus().Id != newStatus.Id {
+			t.Fatalf("unexpected created task %d in %s", createdTask.GetId(), createdTask.GetStatus().Id)
+		}
+		if assignee, _ := fakeModel.CreatedTaskAssignee(3); assignee.GetId() != 7 {
+			t.Fatalf("unexpected assignee %d", assignee.GetId())
+		}
+
+		calls := neaktor.CallsOf("Task.UpdateStatus")
+		if len(calls) != 2 || calls[0].Args[0].(neaktor_api.ModelStatus).Id != doneStatus.Id {
+			t.Fatalf("unexpected calls %v", calls)
+		}
+	})
+
+	t.Run("Titles", func(t *testing.T) {
+		neaktor, _ := newNeaktor()
+
+		model := neaktor.MustGetModelByTitle("Заказ")
+		if status, err := model.GetStatus("Новый Заказ"); err != nil || status.Id != "s_new" {
+			t.Fatalf("expected the status matched case insensitively, got %v, %v", status, err)
+		}
+		if fields, err := model.GetFields([]string{"EMAIL", "телефон"}); err != nil || len(fields) != 1 || fields["EMAIL"].Id != "f_email" {
+			t.Fatalf("expected the found fields only, got %v, %v", fields, err)
+		}
+		if _, err := model.GetStatuses([]string{"отменен"}); !errors.Is(err, neaktor_api.ErrModelStatusNotFound) {
+			t.Fatalf("expected ErrModelStatusNotFound, got %v", err)
+		}
+	})
+
+	t.Run("TypedModel", func(t *testing.T) {
+		type Order struct {
+			Email    string `neaktor:"email"`
+			Delivery string `neaktor:"доставка,omitempty,optional"`
+		}
+
+		neaktor, fakeModel := newNeaktor()
+
+		model := neaktor.MustGetModelByTitle("Заказ")
+		fakeModel.AddCustomFieldOption(model.MustGetField("доставка"), "o1", "курьер")
+
+		orders := neaktor_api.NewTypedModel[Order](model)
+		id := orders.MustCreate(Order{Email: "first@mail.ru", Delivery: "курьер"})
+		orders.MustUpdate(id, Order{Email: "second@mail.ru"})
+		if calls := neaktor.CallsOf("Model.GetTaskById"); len(calls) != 0 {
+			t.Fatalf("expected the task updated by id without reading it, got %v", calls)
+		}
+		if calls := neaktor.CallsOf("Model.UpdateTaskFields"); len(calls) != 1 || calls[0].Args[0] != id {
+			t.Fatalf("expected a single update by id, got %v", calls)
+		}
+		if err := orders.Update(id+100, Order{Email: "missing@mail.ru"}); !errors.Is(err, neaktor_api.ErrTaskNotFound) {
+			t.Fatalf("expected ErrTaskNotFound, got %v", err)
+		}
+
+		if order := orders.MustGetById(id); order != (Order{Email: "second@mail.ru", Delivery: "курьер"}) {
+			t.Fatalf("unexpected order %+v", order)
+		}
+		if fields := fakeModel.Tasks()[0].Fields(); fields[1].Value != "o1" {
+			t.Fatalf("expected the option id to be stored, got %v", fields[1].Value)
+		}
+
+		orders.MustCreate(Order{Email: "third@mail.ru"})
+
+		iterator := orders.Iterate(model.Query().Statuses(model.MustGetStatus("новый заказ")).OrderBy(model.MustGetField("email").Id, neaktor_api.SortDesc))
+		var emails []string
+		for iterator.Next() {
+			emails = append(emails, iterator.Value().Email)
+		}
+		if err := iterator.Err(); err != nil || len(emails) != 2 || emails[0] != "third@mail.ru" {
+			t.Fatalf("unexpected emails %v, %v", emails, err)
+		}
+
+		if _, err := neaktor_api.NewTypedModel[struct {
+			Phone string `neaktor:"телефон"`
+		}](model).GetById(id); !errors.Is(err, neaktor_api.ErrModelFieldNotFound) {
+			t.Fatalf("expected ErrModelFieldNotFound, got %v", err)
+		}
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		neaktor, _ := newNeaktor()
+
+		neaktor.FailNext("Neaktor.GetModelByTitle", neaktor_api.ErrCode500)
+		if _, err := neaktor.GetModelByTitle("Заказ"); !errors.Is(err, neaktor_api.ErrCode500) {
+			t.Fatalf("expected the queued error, got %v", err)
+		}
+		model := neaktor.MustGetModelByTitle("Заказ")
+
+		neaktor.SetError("Model.GetTasksByStatus", neaktor_api.ErrCode429)
+		for i := 0; i < 2; i++ {
+			if _, err := model.GetTasksByStatus(model.MustGetStatus("новый заказ")); !errors.Is(err, neaktor_api.ErrCode429) {
+				t.Fatalf("expected the persistent error, got %v", err)
+			}
+		}
+		neaktor.SetError("Model.GetTasksByStatus", nil)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if _, err := model.GetTasksByStatusWithContext(ctx, model.MustGetStatus("новый заказ")); !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected the context error, got %v", err)
+		}
+
+		if _, err := neaktor.GetModelByTitle("Доставка"); !errors.Is(err, neaktor_api.ErrModelNotFound) {
+			t.Fatalf("expected ErrModelNotFound, got %v", err)
+		}
+		if calls := neaktor.CallsOf("Neaktor.GetModelByTitle"); len(calls) != 3 {
+			t.Fatalf("expected 3 calls, got %d", len(calls))
+		}
+
+		neaktor.Reset()
+		if calls := neaktor.Calls(); len(calls) != 0 {
+			t.Fatalf("expected no calls after Reset, got %d", len(calls))
+		}
+	})
+}
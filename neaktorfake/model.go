@@ -0,0 +1,998 @@
+package neaktorfake
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	neaktor_api "github.com/tanreon/go-neaktor-api"
+)
+
+var _ neaktor_api.IModel = (*Model)(nil)
+
+type Model struct {
+	*recorder
+
+	id         string
+	statuses   []neaktor_api.ModelStatus
+	fields     []neaktor_api.ModelField
+	tasks      []*Task
+	nextTaskId int
+
+	customFieldOptions map[string]map[string]string           // field id to option id to value
+	assignees          map[string][]neaktor_api.ModelAssignee // status id to assignees
+	createdTasks       map[int]neaktor_api.ModelAssignee      // task id to the assignee passed to CreateTask
+}
+
+func newModel(recorder *recorder, id string, statuses []neaktor_api.ModelStatus, fields []neaktor_api.ModelField) *Model {
+	return &Model{
+		recorder:           recorder,
+		id:                 id,
+		statuses:           statuses,
+		fields:             fields,
+		nextTaskId:         1,
+		customFieldOptions: make(map[string]map[string]string, 0),
+		assignees:          make(map[string][]neaktor_api.ModelAssignee, 0),
+		createdTasks:       make(map[int]neaktor_api.ModelAssignee, 0),
+	}
+}
+
+// AddTask stores a task in the status and returns its copy, the task id is assigned in order starting from 1
+func (m *Model) AddTask(status neaktor_api.ModelStatus, fields []neaktor_api.TaskField) *Task {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	return m.addTask(status, fields).snapshot()
+}
+
+func (m *Model) addTask(status neaktor_api.ModelStatus, fields []neaktor_api.TaskField) *Task {
+	task := &Task{
+		recorder:  m.recorder,
+		model:     m,
+		status:    status,
+		id:        m.nextTaskId,
+		idx:       fmt.Sprintf("T-%d", m.nextTaskId),
+		startDate: time.Now(),
+		fields:    append([]neaktor_api.TaskField{}, fields...),
+	}
+	m.nextTaskId++
+
+	m.tasks = append(m.tasks, task)
+
+	return task
+}
+
+// AddCustomFieldOption stores an option returned by GetCustomFieldOptionId and GetCustomFieldValue
+func (m *Model) AddCustomFieldOption(field neaktor_api.ModelField, optionId string, value string) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if _, present := m.customFieldOptions[field.Id]; !present {
+		m.customFieldOptions[field.Id] = make(map[string]string, 0)
+	}
+	m.customFieldOptions[field.Id][optionId] = value
+}
+
+// AddAssignee stores an assignee returned by GetAssignee for the status
+func (m *Model) AddAssignee(status neaktor_api.ModelStatus, assignee neaktor_api.ModelAssignee) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.assignees[status.Id] = append(m.assignees[status.Id], assignee)
+}
+
+// Tasks returns copies of all stored tasks, including the created ones
+func (m *Model) Tasks() (tasks []*Task) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	for _, task := range m.tasks {
+		tasks = append(tasks, task.snapshot())
+	}
+
+	return tasks
+}
+
+// CreatedTaskAssignee returns the assignee passed to CreateTask for the task
+func (m *Model) CreatedTaskAssignee(taskId int) (assignee neaktor_api.ModelAssignee, present bool) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	assignee, present = m.createdTasks[taskId]
+
+	return assignee, present
+}
+
+//
+
+func (m *Model) GetId() string {
+	return m.id
+}
+
+func (m *Model) GetAllStatuses() (statuses map[string]neaktor_api.ModelStatus) {
+	statuses = make(map[string]neaktor_api.ModelStatus, len(m.statuses))
+	for _, status := range m.statuses {
+		statuses[status.Id] = status
+	}
+
+	return statuses
+}
+
+func (m *Model) GetAllFields() (fields map[string]neaktor_api.ModelField) {
+	fields = make(map[string]neaktor_api.ModelField, len(m.fields))
+	for _, field := range m.fields {
+		fields[field.Id] = field
+	}
+
+	return fields
+}
+
+// GetStatuses matches the titles case insensitively and returns the found statuses, like the api client it fails only
+// when none is found
+func (m *Model) GetStatuses(titles []string) (statuses map[string]neaktor_api.ModelStatus, err error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	statuses = make(map[string]neaktor_api.ModelStatus, 0)
+
+	if err := m.record(context.Background(), "Model.GetStatuses", titles); err != nil {
+		return statuses, err
+	}
+
+	for _, status := range m.statuses {
+		for _, title := range titles {
+			if strings.EqualFold(status.Name, title) {
+				statuses[title] = status
+			}
+		}
+	}
+
+	if len(statuses) <= 0 {
+		return statuses, neaktor_api.ErrModelStatusNotFound
+	}
+
+	return statuses, err
+}
+
+func (m *Model) MustGetStatuses(titles []string) (statuses map[string]neaktor_api.ModelStatus) {
+	var err error
+	statuses, err = m.GetStatuses(titles)
+	if err != nil {
+		panic(err)
+	}
+
+	return statuses
+}
+
+// GetFields matches the titles case insensitively and returns the found fields, like the api client it fails only when
+// none is found
+func (m *Model) GetFields(titles []string) (fields map[string]neaktor_api.ModelField, err error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	fields = make(map[string]neaktor_api.ModelField, 0)
+
+	if err := m.record(context.Background(), "Model.GetFields", titles); err != nil {
+		return fields, err
+	}
+
+	for _, field := range m.fields {
+		for _, title := range titles {
+			if strings.EqualFold(field.Name, title) {
+				fields[title] = field
+			}
+		}
+	}
+
+	if len(fields) <= 0 {
+		return fields, neaktor_api.ErrModelFieldNotFound
+	}
+
+	return fields, err
+}
+
+func (m *Model) MustGetFields(titles []string) (fields map[string]neaktor_api.ModelField) {
+	var err error
+	fields, err = m.GetFields(titles)
+	if err != nil {
+		panic(err)
+	}
+
+	return fields
+}
+
+func (m *Model) GetStatus(title string) (status neaktor_api.ModelStatus, err error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if err := m.record(context.Background(), "Model.GetStatus", title); err != nil {
+		return status, err
+	}
+
+	for _, status := range m.statuses {
+		if strings.EqualFold(status.Name, title) {
+			return status, err
+		}
+	}
+
+	return status, neaktor_api.ErrModelStatusNotFound
+}
+
+func (m *Model) MustGetStatus(title string) (status neaktor_api.ModelStatus) {
+	var err error
+	status, err = m.GetStatus(title)
+	if err != nil {
+		panic(err)
+	}
+
+	return status
+}
+
+func (m *Model) GetField(title string) (field neaktor_api.ModelField, err error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if err := m.record(context.Background(), "Model.GetField", title); err != nil {
+		return field, err
+	}
+
+	for _, field := range m.fields {
+		if strings.EqualFold(field.Name, title) {
+			return field, err
+		}
+	}
+
+	return field, neaktor_api.ErrModelFieldNotFound
+}
+
+func (m *Model) MustGetField(title string) (field neaktor_api.ModelField) {
+	var err error
+	field, err = m.GetField(title)
+	if err != nil {
+		panic(err)
+	}
+
+	return field
+}
+
+func (m *Model) GetCustomFieldOptionId(field neaktor_api.ModelField, value string) (optionId string, err error) {
+	return m.GetCustomFieldOptionIdWithContext(context.Background(), field, value)
+}
+
+func (m *Model) GetCustomFieldOptionIdWithContext(ctx context.Context, field neaktor_api.ModelField, value string) (optionId string, err error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if err := m.record(ctx, "Model.GetCustomFieldOptionId", field, value); err != nil {
+		return optionId, err
+	}
+
+	for optionId, optionValue := range m.customFieldOptions[field.Id] {
+		if optionValue == value {
+			return optionId, err
+		}
+	}
+
+	return optionId, neaktor_api.ErrModelCustomFieldOptionNotFound
+}
+
+func (m *Model) MustGetCustomFieldOptionId(field neaktor_api.ModelField, value string) (optionId string) {
+	var err error
+	optionId, err = m.GetCustomFieldOptionId(field, value)
+	if err != nil {
+		panic(err)
+	}
+
+	return optionId
+}
+
+func (m *Model) GetCustomFieldValue(field neaktor_api.ModelField, optionId string) (value string, err error) {
+	return m.GetCustomFieldValueWithContext(context.Background(), field, optionId)
+}
+
+func (m *Model) GetCustomFieldValueWithContext(ctx context.Context, field neaktor_api.ModelField, optionId string) (value string, err error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if err := m.record(ctx, "Model.GetCustomFieldValue", field, optionId); err != nil {
+		return value, err
+	}
+
+	return m.customFieldValue(field, optionId)
+}
+
+func (m *Model) customFieldValue(field neaktor_api.ModelField, optionId string) (value string, err error) {
+	value, present := m.customFieldOptions[field.Id][optionId]
+	if !present {
+		return value, neaktor_api.ErrModelCustomFieldValueNotFound
+	}
+
+	return value, err
+}
+
+func (m *Model) MustGetCustomFieldValue(field neaktor_api.ModelField, optionId string) (value string) {
+	var err error
+	value, err = m.GetCustomFieldValue(field, optionId)
+	if err != nil {
+		panic(err)
+	}
+
+	return value
+}
+
+func (m *Model) GetFieldType(field neaktor_api.ModelField) (fieldType neaktor_api.FieldType, err error) {
+	return m.GetFieldTypeWithContext(context.Background(), field)
+}
+
+// GetFieldTypeWithContext returns the field type, the fields added without one are select ones when they have options
+func (m *Model) GetFieldTypeWithContext(ctx context.Context, field neaktor_api.ModelField) (fieldType neaktor_api.FieldType, err error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if err := m.record(ctx, "Model.GetFieldType", field); err != nil {
+		return fieldType, err
+	}
+
+	if field.Type == neaktor_api.FieldTypeUnknown && len(m.customFieldOptions[field.Id]) > 0 {
+		return neaktor_api.FieldTypeSelect, err
+	}
+
+	return field.Type, err
+}
+
+func (m *Model) MustGetFieldType(field neaktor_api.ModelField) (fieldType neaktor_api.FieldType) {
+	var err error
+	fieldType, err = m.GetFieldType(field)
+	if err != nil {
+		panic(err)
+	}
+
+	return fieldType
+}
+
+func (m *Model) GetFieldOptions(field neaktor_api.ModelField) (options []neaktor_api.CustomFieldOption, err error) {
+	return m.GetFieldOptionsWithContext(context.Background(), field)
+}
+
+// GetFieldOptionsWithContext returns the added options of the field, sorted by id
+func (m *Model) GetFieldOptionsWithContext(ctx context.Context, field neaktor_api.ModelField) (options []neaktor_api.CustomFieldOption, err error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if err := m.record(ctx, "Model.GetFieldOptions", field); err != nil {
+		return options, err
+	}
+
+	for optionId, optionValue := range m.customFieldOptions[field.Id] {
+		options = append(options, neaktor_api.NewCustomFieldOption(optionId, optionValue))
+	}
+	sort.Slice(options, func(i, j int) bool {
+		return options[i].GetId() < options[j].GetId()
+	})
+
+	return options, err
+}
+
+func (m *Model) MustGetFieldOptions(field neaktor_api.ModelField) (options []neaktor_api.CustomFieldOption) {
+	var err error
+	options, err = m.GetFieldOptions(field)
+	if err != nil {
+		panic(err)
+	}
+
+	return options
+}
+
+func (m *Model) GetAssignee(status neaktor_api.ModelStatus, name string) (assignee neaktor_api.ModelAssignee, err error) {
+	return m.GetAssigneeWithContext(context.Background(), status, name)
+}
+
+func (m *Model) GetAssigneeWithContext(ctx context.Context, status neaktor_api.ModelStatus, name string) (assignee neaktor_api.ModelAssignee, err error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if err := m.record(ctx, "Model.GetAssignee", status, name); err != nil {
+		return assignee, err
+	}
+
+	for _, assignee := range m.assignees[status.Id] {
+		if assignee.GetName() == name {
+			return assignee, err
+		}
+	}
+
+	return assignee, neaktor_api.ErrModelAssigneeNotFound
+}
+
+func (m *Model) MustGetAssignee(status neaktor_api.ModelStatus, name string) (assignee neaktor_api.ModelAssignee) {
+	var err error
+	assignee, err = m.GetAssignee(status, name)
+	if err != nil {
+		panic(err)
+	}
+
+	return assignee
+}
+
+func (m *Model) GetTasksByStatus(status neaktor_api.ModelStatus) (tasks []neaktor_api.ITask, err error) {
+	return m.GetTasksByStatusWithContext(context.Background(), status)
+}
+
+func (m *Model) GetTasksByStatusWithContext(ctx context.Context, status neaktor_api.ModelStatus) (tasks []neaktor_api.ITask, err error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if err := m.record(ctx, "Model.GetTasksByStatus", status); err != nil {
+		return tasks, err
+	}
+
+	return m.findTasks([]neaktor_api.ModelStatus{status}, nil), err
+}
+
+func (m *Model) MustGetTasksByStatus(status neaktor_api.ModelStatus) (tasks []neaktor_api.ITask) {
+	var err error
+	tasks, err = m.GetTasksByStatus(status)
+	if err != nil {
+		panic(err)
+	}
+
+	return tasks
+}
+
+func (m *Model) GetTasksByStatuses(statuses []neaktor_api.ModelStatus) (tasks []neaktor_api.ITask, err error) {
+	return m.GetTasksByStatusesWithContext(context.Background(), statuses)
+}
+
+func (m *Model) GetTasksByStatusesWithContext(ctx context.Context, statuses []neaktor_api.ModelStatus) (tasks []neaktor_api.ITask, err error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if err := m.record(ctx, "Model.GetTasksByStatuses", statuses); err != nil {
+		return tasks, err
+	}
+
+	// the real client requests the statuses one by one, so the tasks are grouped by status
+	for _, status := range statuses {
+		tasks = append(tasks, m.findTasks([]neaktor_api.ModelStatus{status}, nil)...)
+	}
+
+	return tasks, err
+}
+
+func (m *Model) MustGetTasksByStatuses(statuses []neaktor_api.ModelStatus) (tasks []neaktor_api.ITask) {
+	var err error
+	tasks, err = m.GetTasksByStatuses(statuses)
+	if err != nil {
+		panic(err)
+	}
+
+	return tasks
+}
+
+func (m *Model) GetTasksByStatusAndFields(status neaktor_api.ModelStatus, fields []neaktor_api.TaskField) (tasks []neaktor_api.ITask, err error) {
+	return m.GetTasksByStatusAndFieldsWithContext(context.Background(), status, fields)
+}
+
+func (m *Model) GetTasksByStatusAndFieldsWithContext(ctx context.Context, status neaktor_api.ModelStatus, fields []neaktor_api.TaskField) (tasks []neaktor_api.ITask, err error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if err := m.record(ctx, "Model.GetTasksByStatusAndFields", status, fields); err != nil {
+		return tasks, err
+	}
+	if err := checkFields(fields); err != nil {
+		return tasks, err
+	}
+
+	return m.findTasks([]neaktor_api.ModelStatus{status}, fields), err
+}
+
+func (m *Model) MustGetTasksByStatusAndFields(status neaktor_api.ModelStatus, fields []neaktor_api.TaskField) (tasks []neaktor_api.ITask) {
+	var err error
+	tasks, err = m.GetTasksByStatusAndFields(status, fields)
+	if err != nil {
+		panic(err)
+	}
+
+	return tasks
+}
+
+func (m *Model) GetTasksByFields(fields []neaktor_api.TaskField) (tasks []neaktor_api.ITask, err error) {
+	return m.GetTasksByFieldsWithContext(context.Background(), fields)
+}
+
+func (m *Model) GetTasksByFieldsWithContext(ctx context.Context, fields []neaktor_api.TaskField) (tasks []neaktor_api.ITask, err error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if err := m.record(ctx, "Model.GetTasksByFields", fields); err != nil {
+		return tasks, err
+	}
+	if err := checkFields(fields); err != nil {
+		return tasks, err
+	}
+
+	return m.findTasks(nil, fields), err
+}
+
+func (m *Model) MustGetTasksByFields(fields []neaktor_api.TaskField) (tasks []neaktor_api.ITask) {
+	var err error
+	tasks, err = m.GetTasksByFields(fields)
+	if err != nil {
+		panic(err)
+	}
+
+	return tasks
+}
+
+func (m *Model) GetTaskById(id int) (task neaktor_api.ITask, err error) {
+	return m.GetTaskByIdWithContext(context.Background(), id)
+}
+
+func (m *Model) GetTaskByIdWithContext(ctx context.Context, id int) (task neaktor_api.ITask, err error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if err := m.record(ctx, "Model.GetTaskById", id); err != nil {
+		return task, err
+	}
+
+	return m.findTask(id)
+}
+
+func (m *Model) findTask(id int) (task neaktor_api.ITask, err error) {
+	for _, storedTask := range m.tasks {
+		if storedTask.id == id {
+			return storedTask.snapshot(), err
+		}
+	}
+
+	return task, neaktor_api.ErrTaskNotFound
+}
+
+func (m *Model) MustGetTaskById(id int) (task neaktor_api.ITask) {
+	var err error
+	task, err = m.GetTaskById(id)
+	if err != nil {
+		panic(err)
+	}
+
+	return task
+}
+
+func (m *Model) CountTasksByStatus(status neaktor_api.ModelStatus) (count int, err error) {
+	return m.CountTasksByStatusWithContext(context.Background(), status)
+}
+
+func (m *Model) CountTasksByStatusWithContext(ctx context.Context, status neaktor_api.ModelStatus) (count int, err error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if err := m.record(ctx, "Model.CountTasksByStatus", status); err != nil {
+		return count, err
+	}
+
+	return len(m.findTasks([]neaktor_api.ModelStatus{status}, nil)), err
+}
+
+func (m *Model) CountTasksByStatuses(statuses []neaktor_api.ModelStatus) (count int, err error) {
+	return m.CountTasksByStatusesWithContext(context.Background(), statuses)
+}
+
+func (m *Model) CountTasksByStatusesWithContext(ctx context.Context, statuses []neaktor_api.ModelStatus) (count int, err error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if err := m.record(ctx, "Model.CountTasksByStatuses", statuses); err != nil {
+		return count, err
+	}
+
+	for _, status := range statuses {
+		count += len(m.findTasks([]neaktor_api.ModelStatus{status}, nil))
+	}
+
+	return count, err
+}
+
+func (m *Model) CountTasksByStatusAndFields(status neaktor_api.ModelStatus, fields []neaktor_api.TaskField) (count int, err error) {
+	return m.CountTasksByStatusAndFieldsWithContext(context.Background(), status, fields)
+}
+
+func (m *Model) CountTasksByStatusAndFieldsWithContext(ctx context.Context, status neaktor_api.ModelStatus, fields []neaktor_api.TaskField) (count int, err error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if err := m.record(ctx, "Model.CountTasksByStatusAndFields", status, fields); err != nil {
+		return count, err
+	}
+	if err := checkFields(fields); err != nil {
+		return count, err
+	}
+
+	return len(m.findTasks([]neaktor_api.ModelStatus{status}, fields)), err
+}
+
+func (m *Model) CountTasksByFields(fields []neaktor_api.TaskField) (count int, err error) {
+	return m.CountTasksByFieldsWithContext(context.Background(), fields)
+}
+
+func (m *Model) CountTasksByFieldsWithContext(ctx context.Context, fields []neaktor_api.TaskField) (count int, err error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if err := m.record(ctx, "Model.CountTasksByFields", fields); err != nil {
+		return count, err
+	}
+	if err := checkFields(fields); err != nil {
+		return count, err
+	}
+
+	return len(m.findTasks(nil, fields)), err
+}
+
+func (m *Model) IsTasksByStatusExists(status neaktor_api.ModelStatus) (isExists bool, err error) {
+	return m.IsTasksByStatusExistsWithContext(context.Background(), status)
+}
+
+func (m *Model) IsTasksByStatusExistsWithContext(ctx context.Context, status neaktor_api.ModelStatus) (isExists bool, err error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if err := m.record(ctx, "Model.IsTasksByStatusExists", status); err != nil {
+		return isExists, err
+	}
+
+	return len(m.findTasks([]neaktor_api.ModelStatus{status}, nil)) > 0, err
+}
+
+func (m *Model) IsTasksByStatusesExists(statuses []neaktor_api.ModelStatus) (isExists bool, err error) {
+	return m.IsTasksByStatusesExistsWithContext(context.Background(), statuses)
+}
+
+func (m *Model) IsTasksByStatusesExistsWithContext(ctx context.Context, statuses []neaktor_api.ModelStatus) (isExists bool, err error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if err := m.record(ctx, "Model.IsTasksByStatusesExists", statuses); err != nil {
+		return isExists, err
+	}
+
+	// like the api client, no statuses are no tasks
+	for _, status := range statuses {
+		if len(m.findTasks([]neaktor_api.ModelStatus{status}, nil)) > 0 {
+			return true, err
+		}
+	}
+
+	return isExists, err
+}
+
+func (m *Model) IsTasksByStatusAndFieldsExists(status neaktor_api.ModelStatus, fields []neaktor_api.TaskField) (isExists bool, err error) {
+	return m.IsTasksByStatusAndFieldsExistsWithContext(context.Background(), status, fields)
+}
+
+func (m *Model) IsTasksByStatusAndFieldsExistsWithContext(ctx context.Context, status neaktor_api.ModelStatus, fields []neaktor_api.TaskField) (isExists bool, err error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if err := m.record(ctx, "Model.IsTasksByStatusAndFieldsExists", status, fields); err != nil {
+		return isExists, err
+	}
+	if err := checkFields(fields); err != nil {
+		return isExists, err
+	}
+
+	return len(m.findTasks([]neaktor_api.ModelStatus{status}, fields)) > 0, err
+}
+
+func (m *Model) IsTasksByFieldsExists(fields []neaktor_api.TaskField) (isExists bool, err error) {
+	return m.IsTasksByFieldsExistsWithContext(context.Background(), fields)
+}
+
+func (m *Model) IsTasksByFieldsExistsWithContext(ctx context.Context, fields []neaktor_api.TaskField) (isExists bool, err error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if err := m.record(ctx, "Model.IsTasksByFieldsExists", fields); err != nil {
+		return isExists, err
+	}
+	if err := checkFields(fields); err != nil {
+		return isExists, err
+	}
+
+	return len(m.findTasks(nil, fields)) > 0, err
+}
+
+func (m *Model) CreateTask(assignee neaktor_api.ModelAssignee, fields []neaktor_api.TaskField) (task neaktor_api.ITask, err error) {
+	return m.CreateTaskWithContext(context.Background(), assignee, fields)
+}
+
+// CreateTaskWithContext stores the task in the first model status, see CreatedTaskAssignee
+func (m *Model) CreateTaskWithContext(ctx context.Context, assignee neaktor_api.ModelAssignee, fields []neaktor_api.TaskField) (task neaktor_api.ITask, err error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if err := m.record(ctx, "Model.CreateTask", assignee, fields); err != nil {
+		return task, err
+	}
+
+	var status neaktor_api.ModelStatus
+	if len(m.statuses) > 0 {
+		status = m.statuses[0]
+	}
+
+	fields, err = m.translateOptionValues(fields)
+	if err != nil {
+		return task, err
+	}
+
+	createdTask := m.addTask(status, fields)
+	m.createdTasks[createdTask.id] = assignee
+
+	return createdTask.snapshot(), err
+}
+
+func (m *Model) MustCreateTask(assignee neaktor_api.ModelAssignee, fields []neaktor_api.TaskField) (task neaktor_api.ITask) {
+	var err error
+	task, err = m.CreateTask(assignee, fields)
+	if err != nil {
+		panic(err)
+	}
+
+	return task
+}
+
+func (m *Model) UpdateTaskFields(id int, fields []neaktor_api.TaskField) (err error) {
+	return m.UpdateTaskFieldsWithContext(context.Background(), id, fields)
+}
+
+// UpdateTaskFieldsWithContext updates the stored task, the copies returned before aren't changed
+func (m *Model) UpdateTaskFieldsWithContext(ctx context.Context, id int, fields []neaktor_api.TaskField) (err error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if err := m.record(ctx, "Model.UpdateTaskFields", id, fields); err != nil {
+		return err
+	}
+
+	fields, err = m.translateOptionValues(fields)
+	if err != nil {
+		return err
+	}
+
+	for _, storedTask := range m.tasks {
+		if storedTask.id == id {
+			storedTask.updateFields(fields)
+			return err
+		}
+	}
+
+	return neaktor_api.ErrTaskNotFound
+}
+
+func (m *Model) MustUpdateTaskFields(id int, fields []neaktor_api.TaskField) {
+	var err error
+	if err = m.UpdateTaskFields(id, fields); err != nil {
+		panic(err)
+	}
+}
+
+// translateOptionValues replaces the option values by the option ids and sets the Option of the fields, like the api
+// client does, only the fields with added options are translated
+func (m *Model) translateOptionValues(fields []neaktor_api.TaskField) (translatedFields []neaktor_api.TaskField, err error) {
+	translatedFields = make([]neaktor_api.TaskField, 0, len(fields))
+	for _, field := range fields {
+		text, isString := field.Value.(string)
+		options := m.customFieldOptions[field.ModelField.Id]
+		if isString && len(options) > 0 {
+			field.Value, field.Option = "", ""
+			for optionId, optionValue := range options {
+				if optionId == text || optionValue == text {
+					field.Value, field.Option = optionId, optionValue
+				}
+			}
+
+			if field.Value == "" {
+				return translatedFields, fmt.Errorf("%w: %s %q", neaktor_api.ErrModelCustomFieldOptionNotFound, field.ModelField.Name, text)
+			}
+		}
+
+		translatedFields = append(translatedFields, field)
+	}
+
+	return translatedFields, err
+}
+
+// checkFields fails on the field values not matching the field types, like the api client building its query
+func checkFields(fields []neaktor_api.TaskField) error {
+	return neaktor_api.NewTaskQuery(nil).WhereFields(fields).Err()
+}
+
+// findTasks returns copies of the tasks in any of the statuses, with all fields equal, nil statuses match any status
+func (m *Model) findTasks(statuses []neaktor_api.ModelStatus, fields []neaktor_api.TaskField) (tasks []neaktor_api.ITask) {
+	for _, task := range m.tasks {
+		if statuses != nil && !isStatusMatched(task.status, statuses) {
+			continue
+		}
+		if !isFieldsMatched(task.fields, fields) {
+			continue
+		}
+
+		tasks = append(tasks, task.snapshot())
+	}
+
+	return tasks
+}
+
+func isStatusMatched(status neaktor_api.ModelStatus, statuses []neaktor_api.ModelStatus) bool {
+	for _, item := range statuses {
+		if item.Id == status.Id {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isFieldsMatched compares the values formatted with fmt, so 1 matches 1.0 only when both are of the same type, the
+// select fields match by the option id and by the option value
+func isFieldsMatched(taskFields []neaktor_api.TaskField, fields []neaktor_api.TaskField) bool {
+	for _, field := range fields {
+		isMatched := false
+		for _, taskField := range taskFields {
+			if taskField.ModelField.Id == field.ModelField.Id && isValueMatched(taskField, field.Value) {
+				isMatched = true
+			}
+		}
+
+		if !isMatched {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isValueMatched(taskField neaktor_api.TaskField, value interface{}) bool {
+	if len(taskField.Option) > 0 && taskField.Option == value {
+		return true
+	}
+
+	return fmt.Sprint(taskField.Value) == fmt.Sprint(value)
+}
+
+func (m *Model) IterateTasksByStatus(status neaktor_api.ModelStatus) neaktor_api.ITaskIterator {
+	return m.IterateTasksByStatusWithContext(context.Background(), status)
+}
+
+func (m *Model) IterateTasksByStatusWithContext(ctx context.Context, status neaktor_api.ModelStatus) neaktor_api.ITaskIterator {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if err := m.record(ctx, "Model.IterateTasksByStatus", status); err != nil {
+		return &taskIterator{err: err}
+	}
+
+	return &taskIterator{tasks: m.findTasks([]neaktor_api.ModelStatus{status}, nil)}
+}
+
+func (m *Model) IterateTasksByStatuses(statuses []neaktor_api.ModelStatus) neaktor_api.ITaskIterator {
+	return m.IterateTasksByStatusesWithContext(context.Background(), statuses)
+}
+
+func (m *Model) IterateTasksByStatusesWithContext(ctx context.Context, statuses []neaktor_api.ModelStatus) neaktor_api.ITaskIterator {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if err := m.record(ctx, "Model.IterateTasksByStatuses", statuses); err != nil {
+		return &taskIterator{err: err}
+	}
+
+	var tasks []neaktor_api.ITask
+	for _, status := range statuses {
+		tasks = append(tasks, m.findTasks([]neaktor_api.ModelStatus{status}, nil)...)
+	}
+
+	return &taskIterator{tasks: tasks}
+}
+
+func (m *Model) IterateTasksByStatusAndFields(status neaktor_api.ModelStatus, fields []neaktor_api.TaskField) neaktor_api.ITaskIterator {
+	return m.IterateTasksByStatusAndFieldsWithContext(context.Background(), status, fields)
+}
+
+func (m *Model) IterateTasksByStatusAndFieldsWithContext(ctx context.Context, status neaktor_api.ModelStatus, fields []neaktor_api.TaskField) neaktor_api.ITaskIterator {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if err := m.record(ctx, "Model.IterateTasksByStatusAndFields", status, fields); err != nil {
+		return &taskIterator{err: err}
+	}
+	if err := checkFields(fields); err != nil {
+		return &taskIterator{err: err}
+	}
+
+	return &taskIterator{tasks: m.findTasks([]neaktor_api.ModelStatus{status}, fields)}
+}
+
+func (m *Model) IterateTasksByFields(fields []neaktor_api.TaskField) neaktor_api.ITaskIterator {
+	return m.IterateTasksByFieldsWithContext(context.Background(), fields)
+}
+
+func (m *Model) IterateTasksByFieldsWithContext(ctx context.Context, fields []neaktor_api.TaskField) neaktor_api.ITaskIterator {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if err := m.record(ctx, "Model.IterateTasksByFields", fields); err != nil {
+		return &taskIterator{err: err}
+	}
+	if err := checkFields(fields); err != nil {
+		return &taskIterator{err: err}
+	}
+
+	return &taskIterator{tasks: m.findTasks(nil, fields)}
+}
+
+func (m *Model) ScanTasksByStatus(status neaktor_api.ModelStatus, scanOptions neaktor_api.ScanOptions) neaktor_api.ITaskScan {
+	return m.ScanTasksByStatusWithContext(context.Background(), status, scanOptions)
+}
+
+func (m *Model) ScanTasksByStatusWithContext(ctx context.Context, status neaktor_api.ModelStatus, scanOptions neaktor_api.ScanOptions) neaktor_api.ITaskScan {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if err := m.record(ctx, "Model.ScanTasksByStatus", status, scanOptions); err != nil {
+		return &taskIterator{err: err}
+	}
+
+	return &taskIterator{tasks: m.findTasks([]neaktor_api.ModelStatus{status}, nil)}
+}
+
+func (m *Model) ScanTasksByStatusAndFields(status neaktor_api.ModelStatus, fields []neaktor_api.TaskField, scanOptions neaktor_api.ScanOptions) neaktor_api.ITaskScan {
+	return m.ScanTasksByStatusAndFieldsWithContext(context.Background(), status, fields, scanOptions)
+}
+
+func (m *Model) ScanTasksByStatusAndFieldsWithContext(ctx context.Context, status neaktor_api.ModelStatus, fields []neaktor_api.TaskField, scanOptions neaktor_api.ScanOptions) neaktor_api.ITaskScan {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if err := m.record(ctx, "Model.ScanTasksByStatusAndFields", status, fields, scanOptions); err != nil {
+		return &taskIterator{err: err}
+	}
+	if err := checkFields(fields); err != nil {
+		return &taskIterator{err: err}
+	}
+
+	return &taskIterator{tasks: m.findTasks([]neaktor_api.ModelStatus{status}, fields)}
+}
+
+// taskIterator iterates the tasks matched at the call, the fake tasks never move during a scan, an injected error is returned by Err before any task
+type taskIterator struct {
+	tasks []neaktor_api.ITask
+	task  neaktor_api.ITask
+	err   error
+}
+
+func (i *taskIterator) Next() bool {
+	if i.err != nil || len(i.tasks) <= 0 {
+		return false
+	}
+
+	i.task = i.tasks[0]
+	i.tasks = i.tasks[1:]
+
+	return true
+}
+
+func (i *taskIterator) Task() neaktor_api.ITask {
+	return i.task
+}
+
+func (i *taskIterator) Err() error {
+	return i.err
+}
+
+func (i *taskIterator) MayBeIncomplete() bool {
+	return false
+}
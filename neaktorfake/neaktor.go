@@ -0,0 +1,92 @@
+package neaktorfake
+
+import (
+	"context"
+
+	"github.com/charmbracelet/log"
+
+	neaktor_api "github.com/tanreon/go-neaktor-api"
+)
+
+var _ neaktor_api.INeaktor = (*Neaktor)(nil)
+
+type Neaktor struct {
+	*recorder
+
+	models map[string]*Model
+	logger *log.Logger
+}
+
+func NewNeaktor() *Neaktor {
+	return &Neaktor{
+		recorder: newRecorder(),
+		models:   make(map[string]*Model, 0),
+	}
+}
+
+// AddModel stores a model returned by GetModelByTitle, the first status is the start one of the created tasks
+func (n *Neaktor) AddModel(title string, id string, statuses []neaktor_api.ModelStatus, fields []neaktor_api.ModelField) *Model {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	model := newModel(n.recorder, id, statuses, fields)
+	n.models[title] = model
+
+	return model
+}
+
+// Logger returns the logger passed to SetLogger
+func (n *Neaktor) Logger() *log.Logger {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	return n.logger
+}
+
+func (n *Neaktor) RefreshToken(clientId, clientSecret, refreshToken string) (err error) {
+	return n.RefreshTokenWithContext(context.Background(), clientId, clientSecret, refreshToken)
+}
+
+func (n *Neaktor) RefreshTokenWithContext(ctx context.Context, clientId, clientSecret, refreshToken string) (err error) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	return n.record(ctx, "Neaktor.RefreshToken", clientId, clientSecret, refreshToken)
+}
+
+func (n *Neaktor) GetModelByTitle(title string) (model neaktor_api.IModel, err error) {
+	return n.GetModelByTitleWithContext(context.Background(), title)
+}
+
+func (n *Neaktor) GetModelByTitleWithContext(ctx context.Context, title string) (model neaktor_api.IModel, err error) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	if err := n.record(ctx, "Neaktor.GetModelByTitle", title); err != nil {
+		return model, err
+	}
+
+	storedModel, present := n.models[title]
+	if !present {
+		return model, neaktor_api.ErrModelNotFound
+	}
+
+	return storedModel, err
+}
+
+func (n *Neaktor) MustGetModelByTitle(title string) (model neaktor_api.IModel) {
+	var err error
+	model, err = n.GetModelByTitle(title)
+	if err != nil {
+		panic(err)
+	}
+
+	return model
+}
+
+func (n *Neaktor) SetLogger(logger *log.Logger) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	n.logger = logger
+}
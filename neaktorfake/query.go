@@ -0,0 +1,234 @@
+package neaktorfake
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	neaktor_api "github.com/tanreon/go-neaktor-api"
+)
+
+func (m *Model) Query() *neaktor_api.TaskQuery {
+	return neaktor_api.NewTaskQuery(m)
+}
+
+func (m *Model) IterateTasks(query *neaktor_api.TaskQuery) neaktor_api.ITaskIterator {
+	return m.IterateTasksWithContext(context.Background(), query)
+}
+
+func (m *Model) IterateTasksWithContext(ctx context.Context, query *neaktor_api.TaskQuery) neaktor_api.ITaskIterator {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if err := m.record(ctx, "Model.IterateTasks", query); err != nil {
+		return &taskIterator{err: err}
+	}
+	if err := query.Err(); err != nil {
+		return &taskIterator{err: err}
+	}
+
+	return &taskIterator{tasks: m.queryTasks(query, true)}
+}
+
+func (m *Model) ScanTasks(query *neaktor_api.TaskQuery, scanOptions neaktor_api.ScanOptions) neaktor_api.ITaskScan {
+	return m.ScanTasksWithContext(context.Background(), query, scanOptions)
+}
+
+func (m *Model) ScanTasksWithContext(ctx context.Context, query *neaktor_api.TaskQuery, scanOptions neaktor_api.ScanOptions) neaktor_api.ITaskScan {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if err := m.record(ctx, "Model.ScanTasks", query, scanOptions); err != nil {
+		return &taskIterator{err: err}
+	}
+	if err := query.Err(); err != nil {
+		return &taskIterator{err: err}
+	}
+
+	return &taskIterator{tasks: m.queryTasks(query, true)}
+}
+
+func (m *Model) CountTasks(query *neaktor_api.TaskQuery) (count int, err error) {
+	return m.CountTasksWithContext(context.Background(), query)
+}
+
+func (m *Model) CountTasksWithContext(ctx context.Context, query *neaktor_api.TaskQuery) (count int, err error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if err := m.record(ctx, "Model.CountTasks", query); err != nil {
+		return count, err
+	}
+	if err := query.Err(); err != nil {
+		return count, err
+	}
+
+	return len(m.queryTasks(query, false)), err
+}
+
+// queryTasks evaluates the query like the api does, status by status, sorted within a status, the tasks are copies
+func (m *Model) queryTasks(query *neaktor_api.TaskQuery, isLimited bool) (tasks []neaktor_api.ITask) {
+	statuses := query.GetStatuses()
+	if len(statuses) <= 0 {
+		statuses = []neaktor_api.ModelStatus{{}}
+	}
+
+	for _, status := range statuses {
+		statusTasks := make([]*Task, 0)
+		for _, task := range m.tasks {
+			if len(status.Id) > 0 && task.status.Id != status.Id {
+				continue
+			}
+			if !isFiltersMatched(task, query.GetFilters()) {
+				continue
+			}
+
+			statusTasks = append(statusTasks, task)
+		}
+
+		sorts := query.GetSorts()
+		sort.SliceStable(statusTasks, func(i, j int) bool {
+			for _, taskSort := range sorts {
+				result, _ := compareValues(statusTasks[i].value(taskSort.FieldId), statusTasks[j].value(taskSort.FieldId))
+				if result == 0 {
+					continue
+				}
+				if taskSort.Order == neaktor_api.SortDesc {
+					return result > 0
+				}
+				return result < 0
+			}
+
+			return false
+		})
+
+		for _, task := range statusTasks {
+			tasks = append(tasks, task.snapshot())
+		}
+	}
+
+	if isLimited && query.GetLimit() > 0 && len(tasks) > query.GetLimit() {
+		tasks = tasks[:query.GetLimit()]
+	}
+
+	return tasks
+}
+
+// value returns the field value or one of the task dates, a zero date is no value
+func (t *Task) value(fieldId string) interface{} {
+	var date time.Time
+	switch fieldId {
+	case neaktor_api.TaskFieldStart:
+		date = t.startDate
+	case neaktor_api.TaskFieldEnd:
+		date = t.endDate
+	case neaktor_api.TaskFieldStatusClosedDate:
+		date = t.statusClosedDate
+	default:
+		for _, field := range t.fields {
+			if field.ModelField.Id == fieldId {
+				return field.Value
+			}
+		}
+		return nil
+	}
+
+	if date.IsZero() {
+		return nil
+	}
+
+	return date
+}
+
+// option returns the option value of a select field, empty for the other fields
+func (t *Task) option(fieldId string) string {
+	for _, field := range t.fields {
+		if field.ModelField.Id == fieldId {
+			return field.Option
+		}
+	}
+
+	return ""
+}
+
+func isFiltersMatched(task *Task, filters []neaktor_api.TaskFilter) bool {
+	for _, filter := range filters {
+		value := task.value(filter.FieldId)
+		if value == nil {
+			return false
+		}
+
+		if !filter.IsRange {
+			if option := task.option(filter.FieldId); len(option) > 0 && option == filter.Value {
+				continue
+			}
+			if result, isComparable := compareValues(value, filter.Value); !isComparable || result != 0 {
+				return false
+			}
+			continue
+		}
+
+		if filter.From != nil {
+			if result, isComparable := compareValues(value, filter.From); !isComparable || result < 0 {
+				return false
+			}
+		}
+		if filter.To != nil {
+			if result, isComparable := compareValues(value, filter.To); !isComparable || result > 0 {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// compareValues compares numbers by value, dates by time and anything else formatted with fmt
+func compareValues(a, b interface{}) (result int, isComparable bool) {
+	if aNumber, isNumber := toFloat(a); isNumber {
+		bNumber, isNumber := toFloat(b)
+		if !isNumber {
+			return result, false
+		}
+
+		switch {
+		case aNumber < bNumber:
+			return -1, true
+		case aNumber > bNumber:
+			return 1, true
+		}
+		return 0, true
+	}
+
+	if aDate, isDate := a.(time.Time); isDate {
+		bDate, isDate := b.(time.Time)
+		if !isDate {
+			return result, false
+		}
+
+		return aDate.Compare(bDate), true
+	}
+
+	return strings.Compare(fmt.Sprint(a), fmt.Sprint(b)), true
+}
+
+func toFloat(value interface{}) (number float64, isNumber bool) {
+	if decimal, isDecimal := value.(neaktor_api.Decimal); isDecimal {
+		return decimal.Float64(), true
+	}
+
+	reflectValue := reflect.ValueOf(value)
+	switch reflectValue.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(reflectValue.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(reflectValue.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return reflectValue.Float(), true
+	}
+
+	return number, false
+}
@@ -0,0 +1,258 @@
+package neaktorfake
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	neaktor_api "github.com/tanreon/go-neaktor-api"
+)
+
+var _ neaktor_api.ITask = (*Task)(nil)
+
+type Task struct {
+	*recorder
+
+	model            *Model
+	status           neaktor_api.ModelStatus
+	id               int
+	idx              string
+	startDate        time.Time
+	endDate          time.Time
+	statusClosedDate time.Time
+	fields           []neaktor_api.TaskField
+	comments         []string
+
+	stored *Task // the stored task of a copy returned to the callers, the updates are applied to both
+}
+
+// snapshot returns a copy of the stored task, so the callers never share the fake state, the lock must be held
+func (t *Task) snapshot() *Task {
+	task := *t
+	task.fields = append([]neaktor_api.TaskField{}, t.fields...)
+	task.comments = append([]string{}, t.comments...)
+	task.stored = t
+
+	return &task
+}
+
+// targets returns the task and its stored task the updates are applied to
+func (t *Task) targets() (tasks []*Task) {
+	tasks = append(tasks, t)
+	if t.stored != nil {
+		tasks = append(tasks, t.stored)
+	}
+
+	return tasks
+}
+
+// Fields returns the current task fields, including the updated ones
+func (t *Task) Fields() (fields []neaktor_api.TaskField) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	return append(fields, t.fields...)
+}
+
+// Comments returns the messages passed to AddComment, in order
+func (t *Task) Comments() (comments []string) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	return append(comments, t.comments...)
+}
+
+//
+
+func (t *Task) GetId() int {
+	return t.id
+}
+
+func (t *Task) GetIdx() string {
+	return t.idx
+}
+
+func (t *Task) GetStartDate() time.Time {
+	return t.startDate
+}
+
+func (t *Task) GetEndDate() time.Time {
+	return t.endDate
+}
+
+func (t *Task) GetStatusClosedDate() time.Time {
+	return t.statusClosedDate
+}
+
+func (t *Task) GetStatus() neaktor_api.ModelStatus {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	return t.status
+}
+
+func (t *Task) GetField(modelField neaktor_api.ModelField) (taskField neaktor_api.TaskField, err error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if err := t.record(context.Background(), "Task.GetField", modelField); err != nil {
+		return taskField, err
+	}
+
+	for _, field := range t.fields {
+		if field.ModelField.Id == modelField.Id {
+			return field, err
+		}
+	}
+
+	return taskField, neaktor_api.ErrTaskFieldNotFound
+}
+
+func (t *Task) MustGetField(modelField neaktor_api.ModelField) (taskField neaktor_api.TaskField) {
+	var err error
+	taskField, err = t.GetField(modelField)
+	if err != nil {
+		panic(err)
+	}
+
+	return taskField
+}
+
+func (t *Task) GetCustomField(modelField neaktor_api.ModelField) (taskField neaktor_api.TaskField, err error) {
+	return t.GetCustomFieldWithContext(context.Background(), modelField)
+}
+
+// GetCustomFieldWithContext translates the option id with the options added by Model.AddCustomFieldOption
+func (t *Task) GetCustomFieldWithContext(ctx context.Context, modelField neaktor_api.ModelField) (taskField neaktor_api.TaskField, err error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if err := t.record(ctx, "Task.GetCustomField", modelField); err != nil {
+		return taskField, err
+	}
+
+	for _, field := range t.fields {
+		if field.ModelField.Id == modelField.Id {
+			value, err := t.model.customFieldValue(modelField, fmt.Sprint(field.Value))
+			if err != nil {
+				return field, err
+			}
+
+			field.Value = value
+			return field, err
+		}
+	}
+
+	return taskField, neaktor_api.ErrTaskFieldNotFound
+}
+
+func (t *Task) MustGetCustomField(modelField neaktor_api.ModelField) (taskField neaktor_api.TaskField) {
+	var err error
+	taskField, err = t.GetCustomField(modelField)
+	if err != nil {
+		panic(err)
+	}
+
+	return taskField
+}
+
+func (t *Task) UpdateFields(fields []neaktor_api.TaskField) error {
+	return t.UpdateFieldsWithContext(context.Background(), fields)
+}
+
+// UpdateFieldsWithContext replaces the values of the present fields and appends the missing ones
+func (t *Task) UpdateFieldsWithContext(ctx context.Context, fields []neaktor_api.TaskField) (err error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if err := t.record(ctx, "Task.UpdateFields", fields); err != nil {
+		return err
+	}
+
+	fields, err = t.model.translateOptionValues(fields)
+	if err != nil {
+		return err
+	}
+
+	for _, task := range t.targets() {
+		task.updateFields(fields)
+	}
+
+	return err
+}
+
+func (t *Task) updateFields(fields []neaktor_api.TaskField) {
+	for _, field := range fields {
+		isUpdated := false
+		for i := range t.fields {
+			if t.fields[i].ModelField.Id == field.ModelField.Id {
+				t.fields[i].Value = field.Value
+				t.fields[i].Option = field.Option
+				isUpdated = true
+			}
+		}
+
+		if !isUpdated {
+			t.fields = append(t.fields, field)
+		}
+	}
+}
+
+func (t *Task) MustUpdateFields(fields []neaktor_api.TaskField) {
+	var err error
+	if err = t.UpdateFields(fields); err != nil {
+		panic(err)
+	}
+}
+
+func (t *Task) UpdateStatus(status neaktor_api.ModelStatus) error {
+	return t.UpdateStatusWithContext(context.Background(), status)
+}
+
+func (t *Task) UpdateStatusWithContext(ctx context.Context, status neaktor_api.ModelStatus) (err error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if err := t.record(ctx, "Task.UpdateStatus", status); err != nil {
+		return err
+	}
+
+	for _, task := range t.targets() {
+		task.status = status
+	}
+
+	return err
+}
+
+func (t *Task) MustUpdateStatus(status neaktor_api.ModelStatus) {
+	var err error
+	if err = t.UpdateStatus(status); err != nil {
+		panic(err)
+	}
+}
+
+func (t *Task) AddComment(message string) error {
+	return t.AddCommentWithContext(context.Background(), message)
+}
+
+func (t *Task) AddCommentWithContext(ctx context.Context, message string) (err error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if err := t.record(ctx, "Task.AddComment", message); err != nil {
+		return err
+	}
+
+	for _, task := range t.targets() {
+		task.comments = append(task.comments, message)
+	}
+
+	return err
+}
+
+func (t *Task) MustAddComment(message string) {
+	var err error
+	if err = t.AddComment(message); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,83 @@
+// Package neaktorotel adapts an OpenTelemetry tracer provider to neaktor_api.ITracer.
+// It's a separate module, so the api client itself doesn't depend on OpenTelemetry.
+//
+//	neaktor := neaktor_api.NewNeaktor(httpClient, apiToken, apiLimit,
+//		neaktor_api.WithTracer(neaktorotel.NewTracer(otel.GetTracerProvider())))
+package neaktorotel
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	neaktor_api "github.com/tanreon/go-neaktor-api"
+)
+
+const InstrumentationName = "github.com/tanreon/go-neaktor-api"
+
+type Tracer struct {
+	tracer trace.Tracer
+}
+
+func NewTracer(tracerProvider trace.TracerProvider) neaktor_api.ITracer {
+	return &Tracer{
+		tracer: tracerProvider.Tracer(InstrumentationName),
+	}
+}
+
+func (t *Tracer) Start(ctx context.Context, name string, attributes ...neaktor_api.Attribute) (context.Context, neaktor_api.ISpan) {
+	spanKind := trace.SpanKindInternal
+	for _, attribute := range attributes {
+		// only the http request spans carry the http method
+		if attribute.Key == neaktor_api.AttributeHttpMethod {
+			spanKind = trace.SpanKindClient
+		}
+	}
+
+	ctx, span := t.tracer.Start(ctx, name, trace.WithSpanKind(spanKind), trace.WithAttributes(convertAttributes(attributes)...))
+
+	return ctx, &Span{span: span}
+}
+
+type Span struct {
+	span trace.Span
+}
+
+func (s *Span) SetAttributes(attributes ...neaktor_api.Attribute) {
+	s.span.SetAttributes(convertAttributes(attributes)...)
+}
+
+func (s *Span) RecordError(err error) {
+	s.span.RecordError(err)
+	s.span.SetStatus(codes.Error, err.Error())
+}
+
+func (s *Span) End() {
+	s.span.End()
+}
+
+func convertAttributes(attributes []neaktor_api.Attribute) []attribute.KeyValue {
+	keyValues := make([]attribute.KeyValue, 0, len(attributes))
+
+	for _, item := range attributes {
+		switch value := item.Value.(type) {
+		case string:
+			keyValues = append(keyValues, attribute.String(item.Key, value))
+		case bool:
+			keyValues = append(keyValues, attribute.Bool(item.Key, value))
+		case int:
+			keyValues = append(keyValues, attribute.Int(item.Key, value))
+		case int64:
+			keyValues = append(keyValues, attribute.Int64(item.Key, value))
+		case float64:
+			keyValues = append(keyValues, attribute.Float64(item.Key, value))
+		default:
+			keyValues = append(keyValues, attribute.String(item.Key, fmt.Sprint(value)))
+		}
+	}
+
+	return keyValues
+}
@@ -0,0 +1,67 @@
+package neaktorotel
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	requrl "github.com/wangluozhe/requests/url"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	neaktor_api "github.com/tanreon/go-neaktor-api"
+)
+
+func TestTracer(t *testing.T) {
+	httpDoer := neaktor_api.HttpDoerFunc(func(ctx context.Context, request *neaktor_api.HttpRequest) (*neaktor_api.HttpResponse, error) {
+		return &neaktor_api.HttpResponse{
+			StatusCode: http.StatusOK,
+			Body:       []byte(`{"data":[{"id":"m1","name":"Заказ"}],"total":1}`),
+		}, nil
+	})
+
+	spanRecorder := tracetest.NewSpanRecorder()
+	tracerProvider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spanRecorder))
+
+	neaktor := neaktor_api.NewNeaktor(*requrl.NewRequest(), "t1o2k3e4n5", 6000, neaktor_api.WithHttpDoer(httpDoer), neaktor_api.WithTracer(NewTracer(tracerProvider)))
+
+	if _, err := neaktor.GetModelByTitle("Заказ"); err != nil {
+		t.Fatal(err)
+	}
+
+	spans := make(map[string]sdktrace.ReadOnlySpan, 0)
+	for _, span := range spanRecorder.Ended() {
+		spans[span.Name()] = span
+	}
+
+	operationSpan, present := spans["Neaktor.GetModelByTitle"]
+	if !present {
+		t.Fatalf("operation span not found in %v", spans)
+	}
+
+	for _, name := range []string{"neaktor.rate_limit", "GET /v1/taskmodels"} {
+		span, present := spans[name]
+		if !present {
+			t.Fatalf("%s span not found in %v", name, spans)
+		}
+		if span.Parent().SpanID() != operationSpan.SpanContext().SpanID() {
+			t.Errorf("%s span is expected to be a child of the operation span", name)
+		}
+	}
+
+	httpSpan := spans["GET /v1/taskmodels"]
+	if httpSpan.SpanKind() != trace.SpanKindClient {
+		t.Errorf("unexpected http span kind: %s", httpSpan.SpanKind())
+	}
+
+	isStatusCodeSet := false
+	for _, attribute := range httpSpan.Attributes() {
+		if string(attribute.Key) == neaktor_api.AttributeHttpStatusCode && attribute.Value.AsInt64() == http.StatusOK {
+			isStatusCodeSet = true
+		}
+	}
+	if !isStatusCodeSet {
+		t.Errorf("status code attribute not found in %v", httpSpan.Attributes())
+	}
+}
@@ -0,0 +1,788 @@
+// Package neaktortest provides an in-memory stand-in for the Neaktor REST API, to run the real client against it:
+//
+//	server := neaktortest.NewServer()
+//	defer server.Close()
+//
+//	server.AddModel(neaktortest.Model{Id: "m1", Name: "Заказ", ...})
+//	neaktor := neaktor_api.NewNeaktor(*requrl.NewRequest(), server.AccessToken(), 6000,
+//		neaktor_api.WithBaseUrl(server.URL), neaktor_api.WithHttpDoer(neaktor_api.NewNetHttpDoer(server.Client())))
+package neaktortest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const DefaultAccessToken = "t1o2k3e4n5"
+
+type Model struct {
+	Id          string
+	Name        string
+	Fields      []Field
+	Statuses    []Status
+	StartStatus string // status id of the created tasks, the first status when empty
+}
+
+type Field struct {
+	Id    string
+	Name  string
+	State string
+	Type  string // not sent when empty
+}
+
+type Status struct {
+	Id     string
+	Name   string
+	Closed bool
+	Type   string
+}
+
+type Task struct {
+	Id       int // assigned by AddTask when 0
+	Idx      string
+	ModelId  string
+	StatusId string
+	Fields   map[string]interface{} // field id to value, as sent in json
+	Assignee *Assignee
+}
+
+type Assignee struct {
+	Id   int
+	Name string
+	Type string
+}
+
+type CustomField struct {
+	Id      string
+	Name    string
+	Type    string
+	Options []Option
+}
+
+type Option struct {
+	Id    string
+	Value string
+}
+
+// Fault makes the server answer matching requests with StatusCode instead of handling them
+type Fault struct {
+	Method     string // any method when empty
+	Path       string // path prefix, e.g. /v1/tasks, any path when empty
+	StatusCode int    // 429 and 5xx get the neaktor error codes in the body
+	RetryAfter string // Retry-After header value, not set when empty
+	Times      int    // number of requests to fail
+}
+
+// Request is a request received by the server, see Requests
+type Request struct {
+	Method string
+	Path   string
+	Query  string
+	Body   string
+}
+
+type Server struct {
+	*httptest.Server
+
+	lock         sync.Mutex
+	models       []Model
+	tasks        map[int]*Task
+	nextTaskId   int
+	comments     map[int][]string
+	customFields map[string]CustomField
+	routings     map[string]map[string][]Assignee // model id to the routing target status id to assignees
+	faults       []*Fault
+	requests     []Request
+
+	accessTokens  map[string]bool
+	clientId      string
+	clientSecret  string
+	refreshToken  string
+	issuedTokens  int
+	isAuthEnabled bool
+}
+
+// NewServer starts a server accepting DefaultAccessToken, see SetAccessToken and SetOauthClient
+func NewServer() *Server {
+	s := &Server{
+		tasks:         make(map[int]*Task, 0),
+		nextTaskId:    1,
+		comments:      make(map[int][]string, 0),
+		customFields:  make(map[string]CustomField, 0),
+		routings:      make(map[string]map[string][]Assignee, 0),
+		accessTokens:  map[string]bool{DefaultAccessToken: true},
+		isAuthEnabled: true,
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+
+	return s
+}
+
+//
+
+// SetAccessToken replaces the accepted access tokens, an empty token disables the authorization check
+func (s *Server) SetAccessToken(accessToken string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.isAuthEnabled = len(accessToken) > 0
+	s.accessTokens = map[string]bool{accessToken: true}
+}
+
+// AccessToken returns one of the accepted access tokens
+func (s *Server) AccessToken() string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for accessToken := range s.accessTokens {
+		return accessToken
+	}
+
+	return ""
+}
+
+// SetOauthClient enables /oauth/token, every refresh invalidates the previous access token and rotates the refresh one
+func (s *Server) SetOauthClient(clientId, clientSecret, refreshToken string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.clientId = clientId
+	s.clientSecret = clientSecret
+	s.refreshToken = refreshToken
+}
+
+// RefreshToken returns the refresh token currently accepted by /oauth/token
+func (s *Server) RefreshToken() string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.refreshToken
+}
+
+func (s *Server) AddModel(model Model) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.models = append(s.models, model)
+}
+
+// AddTask stores the task and returns its id
+func (s *Server) AddTask(task Task) int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.addTask(task)
+}
+
+func (s *Server) addTask(task Task) int {
+	if task.Id <= 0 {
+		task.Id = s.nextTaskId
+	}
+	if task.Id >= s.nextTaskId {
+		s.nextTaskId = task.Id + 1
+	}
+	if len(task.Idx) <= 0 {
+		task.Idx = fmt.Sprintf("T-%d", task.Id)
+	}
+
+	fields := make(map[string]interface{}, len(task.Fields))
+	for id, value := range task.Fields {
+		fields[id] = value
+	}
+	task.Fields = fields
+
+	s.tasks[task.Id] = &task
+
+	return task.Id
+}
+
+func (s *Server) AddCustomField(customField CustomField) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.customFields[customField.Id] = customField
+}
+
+// AddRouting sets the assignees available for tasks routed to the status
+func (s *Server) AddRouting(modelId, statusId string, assignees ...Assignee) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if _, present := s.routings[modelId]; !present {
+		s.routings[modelId] = make(map[string][]Assignee, 0)
+	}
+	s.routings[modelId][statusId] = append(s.routings[modelId][statusId], assignees...)
+}
+
+// InjectFault fails the next fault.Times requests matching the fault
+func (s *Server) InjectFault(fault Fault) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.faults = append(s.faults, &fault)
+}
+
+// Task returns a copy of the stored task
+func (s *Server) Task(id int) (task Task, present bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	storedTask, present := s.tasks[id]
+	if !present {
+		return task, false
+	}
+
+	return copyTask(storedTask), true
+}
+
+// Tasks returns copies of all stored tasks ordered by id
+func (s *Server) Tasks() (tasks []Task) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for _, id := range s.sortedTaskIds() {
+		tasks = append(tasks, copyTask(s.tasks[id]))
+	}
+
+	return tasks
+}
+
+func (s *Server) Comments(taskId int) (comments []string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return append(comments, s.comments[taskId]...)
+}
+
+// Requests returns the requests received so far, including the failed ones
+func (s *Server) Requests() (requests []Request) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return append(requests, s.requests...)
+}
+
+func copyTask(task *Task) Task {
+	taskCopy := *task
+	taskCopy.Fields = make(map[string]interface{}, len(task.Fields))
+	for id, value := range task.Fields {
+		taskCopy.Fields[id] = value
+	}
+
+	return taskCopy
+}
+
+func (s *Server) sortedTaskIds() []int {
+	ids := make([]int, 0, len(s.tasks))
+	for id := range s.tasks {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	return ids
+}
+
+//
+
+var (
+	taskPath         = regexp.MustCompile(`^/v1/tasks/(\d+)$`)
+	createTaskPath   = regexp.MustCompile(`^/v1/tasks/([^/]+)$`)
+	statusChangePath = regexp.MustCompile(`^/v1/tasks/(\d+)/status/change$`)
+	commentPath      = regexp.MustCompile(`^/v1/comments/(\d+)$`)
+	customFieldPath  = regexp.MustCompile(`^/v1/customfields/([^/]+)$`)
+	routingsPath     = regexp.MustCompile(`^/v1/taskmodels/([^/]+)/([^/]+)/routings$`)
+)
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "400", err.Error())
+		return
+	}
+
+	s.requests = append(s.requests, Request{
+		Method: r.Method,
+		Path:   r.URL.Path,
+		Query:  r.URL.RawQuery,
+		Body:   string(body),
+	})
+
+	if s.serveFault(w, r) {
+		return
+	}
+
+	if r.Method == http.MethodPost && r.URL.Path == "/oauth/token" {
+		s.serveOauthToken(w, r, body)
+		return
+	}
+
+	if s.isAuthEnabled && !s.isAuthorized(r.Header.Get("Authorization")) {
+		writeJson(w, http.StatusUnauthorized, map[string]string{"error": "invalid_token", "error_description": "Invalid access token"})
+		return
+	}
+
+	path := r.URL.Path
+	switch {
+	case r.Method == http.MethodGet && path == "/v1/taskmodels":
+		s.serveTaskModels(w, r)
+	case r.Method == http.MethodGet && path == "/v1/tasks":
+		s.serveTasks(w, r)
+	case r.Method == http.MethodGet && taskPath.MatchString(path):
+		s.serveTask(w, atoi(taskPath.FindStringSubmatch(path)[1]))
+	case r.Method == http.MethodPut && taskPath.MatchString(path):
+		s.serveUpdateTask(w, atoi(taskPath.FindStringSubmatch(path)[1]), body)
+	case r.Method == http.MethodPost && statusChangePath.MatchString(path):
+		s.serveStatusChange(w, atoi(statusChangePath.FindStringSubmatch(path)[1]), body)
+	case r.Method == http.MethodPost && commentPath.MatchString(path):
+		s.serveComment(w, atoi(commentPath.FindStringSubmatch(path)[1]), body)
+	case r.Method == http.MethodPost && createTaskPath.MatchString(path):
+		s.serveCreateTask(w, createTaskPath.FindStringSubmatch(path)[1], body)
+	case r.Method == http.MethodGet && customFieldPath.MatchString(path):
+		s.serveCustomField(w, customFieldPath.FindStringSubmatch(path)[1])
+	case r.Method == http.MethodGet && routingsPath.MatchString(path):
+		s.serveRoutings(w, routingsPath.FindStringSubmatch(path)[1])
+	default:
+		writeError(w, http.StatusNotFound, "404", "not found")
+	}
+}
+
+func (s *Server) serveFault(w http.ResponseWriter, r *http.Request) bool {
+	for i, fault := range s.faults {
+		if len(fault.Method) > 0 && fault.Method != r.Method {
+			continue
+		}
+		if !strings.HasPrefix(r.URL.Path, fault.Path) {
+			continue
+		}
+
+		fault.Times--
+		if fault.Times <= 0 {
+			s.faults = append(s.faults[:i], s.faults[i+1:]...)
+		}
+
+		if len(fault.RetryAfter) > 0 {
+			w.Header().Set("Retry-After", fault.RetryAfter)
+		}
+
+		switch {
+		case fault.StatusCode == http.StatusTooManyRequests:
+			writeError(w, fault.StatusCode, "429 TOO_MANY_REQUESTS", "too many requests")
+		case fault.StatusCode >= 500:
+			writeError(w, fault.StatusCode, "500 INTERNAL_SERVER_ERROR", "internal server error")
+		default:
+			writeError(w, fault.StatusCode, strconv.Itoa(fault.StatusCode), http.StatusText(fault.StatusCode))
+		}
+
+		return true
+	}
+
+	return false
+}
+
+func (s *Server) isAuthorized(authorization string) bool {
+	return s.accessTokens[strings.TrimPrefix(authorization, "Bearer ")]
+}
+
+func (s *Server) serveOauthToken(w http.ResponseWriter, r *http.Request, body []byte) {
+	values, err := url.ParseQuery(string(body))
+	if err != nil || len(s.refreshToken) <= 0 {
+		writeJson(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+
+	if values.Get("client_id") != s.clientId || values.Get("client_secret") != s.clientSecret {
+		writeJson(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+	if values.Get("grant_type") != "refresh_token" || values.Get("refresh_token") != s.refreshToken {
+		writeJson(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	s.issuedTokens++
+	accessToken := fmt.Sprintf("access-%d", s.issuedTokens)
+	s.refreshToken = fmt.Sprintf("refresh-%d", s.issuedTokens)
+	s.accessTokens = map[string]bool{accessToken: true}
+	s.isAuthEnabled = true
+
+	writeJson(w, http.StatusOK, map[string]interface{}{
+		"access_token":  accessToken,
+		"token_type":    "bearer",
+		"refresh_token": s.refreshToken,
+		"expires_in":    3600,
+	})
+}
+
+func (s *Server) serveTaskModels(w http.ResponseWriter, r *http.Request) {
+	data := make([]interface{}, 0)
+
+	for _, model := range s.models {
+		fields := make([]interface{}, 0)
+		for _, field := range model.Fields {
+			fieldJson := map[string]interface{}{"id": field.Id, "name": field.Name, "state": field.State}
+			if len(field.Type) > 0 {
+				fieldJson["type"] = field.Type
+			}
+			fields = append(fields, fieldJson)
+		}
+		statuses := make([]interface{}, 0)
+		for _, status := range model.Statuses {
+			statuses = append(statuses, map[string]interface{}{"id": status.Id, "name": status.Name, "closed": status.Closed, "type": status.Type})
+		}
+
+		data = append(data, map[string]interface{}{
+			"id":            model.Id,
+			"name":          model.Name,
+			"fields":        fields,
+			"statuses":      statuses,
+			"startStatus":   s.startStatus(model),
+			"canCreateTask": true,
+			"roles":         []interface{}{},
+		})
+	}
+
+	writeJson(w, http.StatusOK, map[string]interface{}{
+		"data":  data,
+		"page":  0,
+		"size":  len(data),
+		"total": len(data),
+	})
+}
+
+// serveTasks filters by model_id, status_id and any other query param as a field id, size defaults to 50. Like the api
+// it has no range or sort params, a client sending them filters by an unknown field and gets no tasks.
+func (s *Server) serveTasks(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	size := 50
+	if value, err := strconv.Atoi(query.Get("size")); err == nil && value > 0 {
+		size = value
+	}
+	page := 0
+	if value, err := strconv.Atoi(query.Get("page")); err == nil && value > 0 {
+		page = value
+	}
+
+	matchedTasks := make([]*Task, 0)
+	for _, id := range s.sortedTaskIds() {
+		task := s.tasks[id]
+		if isTaskMatched(task, query) {
+			matchedTasks = append(matchedTasks, task)
+		}
+	}
+
+	data := make([]interface{}, 0)
+	for i := page * size; i < len(matchedTasks) && i < (page+1)*size; i++ {
+		// the task list refers to the status by name, unlike /v1/tasks/{id}
+		data = append(data, s.taskJson(matchedTasks[i], s.statusName(matchedTasks[i])))
+	}
+
+	links := map[string]string{}
+	if (page+1)*size < len(matchedTasks) {
+		nextQuery := r.URL.Query()
+		nextQuery.Set("page", strconv.Itoa(page+1))
+		links["next"] = s.URL + r.URL.Path + "?" + nextQuery.Encode()
+	}
+
+	writeJson(w, http.StatusOK, map[string]interface{}{
+		"data":  data,
+		"links": links,
+		"page":  page,
+		"size":  size,
+		"total": len(matchedTasks),
+	})
+}
+
+func isTaskMatched(task *Task, query map[string][]string) bool {
+	for key, values := range query {
+		switch key {
+		case "size", "page":
+			continue
+		case "model_id":
+			if task.ModelId != values[0] {
+				return false
+			}
+		case "status_id":
+			if task.StatusId != values[0] {
+				return false
+			}
+		default:
+			value, present := task.Fields[key]
+			if !present || !isValueMatched(value, values[0]) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// isValueMatched compares numbers by value, whatever precision the client formats them with
+func isValueMatched(value interface{}, filter string) bool {
+	if number, ok := value.(float64); ok {
+		filterNumber, err := strconv.ParseFloat(filter, 64)
+		return err == nil && number == filterNumber
+	}
+
+	return fmt.Sprint(value) == filter
+}
+
+func (s *Server) serveTask(w http.ResponseWriter, id int) {
+	task, present := s.tasks[id]
+	if !present {
+		writeError(w, http.StatusNotFound, "404", "task not found")
+		return
+	}
+
+	writeJson(w, http.StatusOK, []interface{}{s.taskJson(task, task.StatusId)})
+}
+
+func (s *Server) serveUpdateTask(w http.ResponseWriter, id int, body []byte) {
+	type UpdateTaskRequest struct {
+		Fields []struct {
+			Id    string      `json:"id"`
+			Value interface{} `json:"value"`
+		} `json:"fields"`
+	}
+
+	task, present := s.tasks[id]
+	if !present {
+		writeError(w, http.StatusNotFound, "404", "task not found")
+		return
+	}
+
+	var updateTaskRequest UpdateTaskRequest
+	if err := json.Unmarshal(body, &updateTaskRequest); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, "422", err.Error())
+		return
+	}
+
+	for _, field := range updateTaskRequest.Fields {
+		task.Fields[field.Id] = field.Value
+	}
+
+	writeJson(w, http.StatusOK, map[string]interface{}{})
+}
+
+func (s *Server) serveStatusChange(w http.ResponseWriter, id int, body []byte) {
+	type UpdateTaskStatusRequest struct {
+		Status string `json:"status"`
+	}
+
+	task, present := s.tasks[id]
+	if !present {
+		writeError(w, http.StatusNotFound, "404", "task not found")
+		return
+	}
+
+	var updateTaskStatusRequest UpdateTaskStatusRequest
+	if err := json.Unmarshal(body, &updateTaskStatusRequest); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, "422", err.Error())
+		return
+	}
+	if _, present := s.status(task.ModelId, updateTaskStatusRequest.Status); !present {
+		writeError(w, http.StatusUnprocessableEntity, "422", "unknown status")
+		return
+	}
+
+	task.StatusId = updateTaskStatusRequest.Status
+
+	writeJson(w, http.StatusOK, map[string]interface{}{})
+}
+
+func (s *Server) serveComment(w http.ResponseWriter, id int, body []byte) {
+	type CreateCommentRequest struct {
+		Text string `json:"text"`
+	}
+
+	if _, present := s.tasks[id]; !present {
+		writeError(w, http.StatusNotFound, "404", "task not found")
+		return
+	}
+
+	var createCommentRequest CreateCommentRequest
+	if err := json.Unmarshal(body, &createCommentRequest); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, "422", err.Error())
+		return
+	}
+
+	s.comments[id] = append(s.comments[id], createCommentRequest.Text)
+
+	writeJson(w, http.StatusOK, map[string]interface{}{})
+}
+
+func (s *Server) serveCreateTask(w http.ResponseWriter, modelId string, body []byte) {
+	type CreateTaskRequest struct {
+		Assignee struct {
+			Id   int    `json:"id"`
+			Type string `json:"type"`
+		} `json:"assignee"`
+		Fields []struct {
+			Id    string      `json:"id"`
+			Value interface{} `json:"value"`
+		} `json:"fields"`
+	}
+
+	var model *Model
+	for i := range s.models {
+		if s.models[i].Id == modelId {
+			model = &s.models[i]
+		}
+	}
+	if model == nil {
+		writeError(w, http.StatusNotFound, "404", "model not found")
+		return
+	}
+
+	var createTaskRequest CreateTaskRequest
+	if err := json.Unmarshal(body, &createTaskRequest); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, "422", err.Error())
+		return
+	}
+
+	task := Task{
+		ModelId:  modelId,
+		StatusId: s.startStatus(*model),
+		Fields:   make(map[string]interface{}, 0),
+	}
+	if createTaskRequest.Assignee.Id > 0 {
+		task.Assignee = &Assignee{Id: createTaskRequest.Assignee.Id, Type: createTaskRequest.Assignee.Type}
+	}
+	for _, field := range createTaskRequest.Fields {
+		task.Fields[field.Id] = field.Value
+	}
+
+	id := s.addTask(task)
+
+	writeJson(w, http.StatusOK, map[string]interface{}{"id": id, "projectId": modelId})
+}
+
+func (s *Server) serveCustomField(w http.ResponseWriter, id string) {
+	customField, present := s.customFields[id]
+	if !present {
+		writeError(w, http.StatusNotFound, "404", "custom field not found")
+		return
+	}
+
+	availableValues := make([]interface{}, 0)
+	for _, option := range customField.Options {
+		availableValues = append(availableValues, map[string]string{"id": option.Id, "value": option.Value})
+	}
+
+	writeJson(w, http.StatusOK, []interface{}{map[string]interface{}{
+		"id":      customField.Id,
+		"type":    customField.Type,
+		"name":    customField.Name,
+		"options": map[string]interface{}{"availableValues": availableValues},
+	}})
+}
+
+// serveRoutings returns every routing of the model, the client picks the one to the status it needs
+func (s *Server) serveRoutings(w http.ResponseWriter, modelId string) {
+	routings := make([]interface{}, 0)
+
+	statusIds := make([]string, 0)
+	for statusId := range s.routings[modelId] {
+		statusIds = append(statusIds, statusId)
+	}
+	sort.Strings(statusIds)
+
+	for _, statusId := range statusIds {
+		assignees := make([]interface{}, 0)
+		for _, assignee := range s.routings[modelId][statusId] {
+			assignees = append(assignees, map[string]interface{}{"id": assignee.Id, "name": assignee.Name, "type": assignee.Type})
+		}
+
+		routings = append(routings, map[string]interface{}{
+			"to":         statusId,
+			"conditions": []interface{}{},
+			"assignees":  assignees,
+		})
+	}
+
+	writeJson(w, http.StatusOK, routings)
+}
+
+//
+
+func (s *Server) taskJson(task *Task, status string) map[string]interface{} {
+	fieldIds := make([]string, 0, len(task.Fields))
+	for id := range task.Fields {
+		fieldIds = append(fieldIds, id)
+	}
+	sort.Strings(fieldIds)
+
+	fields := make([]interface{}, 0)
+	for _, id := range fieldIds {
+		fields = append(fields, map[string]interface{}{"id": id, "value": task.Fields[id], "state": "EDITABLE"})
+	}
+
+	return map[string]interface{}{
+		"id":         task.Id,
+		"idx":        task.Idx,
+		"projectId":  task.ModelId,
+		"modelId":    task.ModelId,
+		"status":     status,
+		"fields":     fields,
+		"canDelete":  true,
+		"parentId":   nil,
+		"subtaskIds": []interface{}{},
+	}
+}
+
+func (s *Server) status(modelId, statusId string) (status Status, present bool) {
+	for _, model := range s.models {
+		if model.Id != modelId {
+			continue
+		}
+		for _, status := range model.Statuses {
+			if status.Id == statusId {
+				return status, true
+			}
+		}
+	}
+
+	return status, false
+}
+
+func (s *Server) statusName(task *Task) string {
+	status, _ := s.status(task.ModelId, task.StatusId)
+
+	return status.Name
+}
+
+func (s *Server) startStatus(model Model) string {
+	if len(model.StartStatus) > 0 || len(model.Statuses) <= 0 {
+		return model.StartStatus
+	}
+
+	return model.Statuses[0].Id
+}
+
+func writeJson(w http.ResponseWriter, statusCode int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(value)
+}
+
+func writeError(w http.ResponseWriter, statusCode int, code, message string) {
+	writeJson(w, statusCode, map[string]string{"type": "error", "code": code, "message": message})
+}
+
+func atoi(value string) int {
+	number, _ := strconv.Atoi(value)
+
+	return number
+}
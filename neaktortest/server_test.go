@@ -0,0 +1,409 @@
+package neaktortest_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	requrl "github.com/wangluozhe/requests/url"
+
+	neaktor_api "github.com/tanreon/go-neaktor-api"
+	"github.com/tanreon/go-neaktor-api/neaktortest"
+)
+
+func newServer(t *testing.T) *neaktortest.Server {
+	server := neaktortest.NewServer()
+	t.Cleanup(server.Close)
+
+	server.AddModel(neaktortest.Model{
+		Id:   "m1",
+		Name: "Заказ",
+		Fields: []neaktortest.Field{
+			{Id: "f_email", Name: "email", State: "EDITABLE"},
+			{Id: "f_amount", Name: "сумма", State: "EDITABLE"},
+			{Id: "f_delivery", Name: "доставка", State: "EDITABLE"},
+			{Id: "f_paid", Name: "оплачен", State: "EDITABLE", Type: "DATE"},
+			{Id: "f_manager", Name: "менеджер", State: "EDITABLE", Type: "USER"},
+			{Id: "f_total", Name: "итого", State: "EDITABLE", Type: "CURRENCY"},
+			{Id: "f_prepaid", Name: "предоплата", State: "EDITABLE"},
+		},
+		Statuses: []neaktortest.Status{
+			{Id: "s_new", Name: "новый заказ", Type: "START"},
+			{Id: "s_done", Name: "выполнен", Closed: true, Type: "END"},
+		},
+	})
+	server.AddCustomField(neaktortest.CustomField{
+		Id:   "f_delivery",
+		Name: "доставка",
+		Type: "SELECT",
+		Options: []neaktortest.Option{
+			{Id: "o_courier", Value: "курьер"},
+			{Id: "o_pickup", Value: "самовывоз"},
+		},
+	})
+	server.AddCustomField(neaktortest.CustomField{Id: "f_prepaid", Name: "предоплата", Type: "CURRENCY"})
+	for i := 0; i < 120; i++ {
+		delivery := "o_courier"
+		if i%2 == 1 {
+			delivery = "o_pickup"
+		}
+
+		server.AddTask(neaktortest.Task{
+			ModelId:  "m1",
+			StatusId: "s_new",
+			Fields: map[string]interface{}{
+				"f_email":    "user@mail.ru",
+				"f_amount":   float64(i % 3),
+				"f_delivery": delivery,
+				"f_paid":     "01-03-2024T09:30:00",
+				"f_manager":  map[string]interface{}{"id": 7, "name": "Менеджер"},
+				"f_total":    map[string]interface{}{"value": 1500.5, "currency": "RUB"},
+				"f_prepaid":  map[string]interface{}{"value": 500, "currency": "RUB"},
+			},
+		})
+	}
+	server.AddRouting("m1", "s_new", neaktortest.Assignee{Id: 7, Name: "Менеджер", Type: "USER"})
+
+	return server
+}
+
+func newNeaktor(server *neaktortest.Server, options ...neaktor_api.NeaktorOption) neaktor_api.INeaktor {
+	options = append([]neaktor_api.NeaktorOption{
+		neaktor_api.WithBaseUrl(server.URL),
+		neaktor_api.WithHttpDoer(neaktor_api.NewNetHttpDoer(server.Client())),
+	}, options...)
+
+	return neaktor_api.NewNeaktor(*requrl.NewRequest(), server.AccessToken(), 6000, options...)
+}
+
+func TestServer(t *testing.T) {
+	t.Run("Tasks", func(t *testing.T) {
+		server := newServer(t)
+		neaktor := newNeaktor(server)
+
+		model := neaktor.MustGetModelByTitle("Заказ")
+		newStatus := model.MustGetStatus("новый заказ")
+		doneStatus := model.MustGetStatus("выполнен")
+		amountField := model.MustGetField("сумма")
+
+		tasks := model.MustGetTasksByStatus(newStatus)
+		if len(tasks) != 120 {
+			t.Fatalf("expected 120 tasks, got %d", len(tasks))
+		}
+
+		tasks = model.MustGetTasksByStatusAndFields(newStatus, []neaktor_api.TaskField{{ModelField: amountField, Value: 1}})
+		if len(tasks) != 40 {
+			t.Fatalf("expected 40 filtered tasks, got %d", len(tasks))
+		}
+
+		task := tasks[0]
+		task.MustUpdateFields([]neaktor_api.TaskField{{ModelField: amountField, Value: 5}})
+		task.MustUpdateStatus(doneStatus)
+		task.MustAddComment("done")
+
+		storedTask, _ := server.Task(task.GetId())
+		if storedTask.StatusId != "s_done" || storedTask.Fields["f_amount"] != float64(5) {
+			t.Fatalf("unexpected stored task: %+v", storedTask)
+		}
+		if comments := server.Comments(task.GetId()); len(comments) != 1 || comments[0] != "done" {
+			t.Fatalf("unexpected comments: %v", comments)
+		}
+
+		createdTask := model.MustCreateTask(model.MustGetAssignee(newStatus, "Менеджер"), []neaktor_api.TaskField{{ModelField: amountField, Value: 10}})
+		if createdTask.GetStatus().Id != "s_new" || len(server.Tasks()) != 121 {
+			t.Fatalf("unexpected created task: %d %v", createdTask.GetId(), createdTask.GetStatus())
+		}
+	})
+
+	t.Run("Query", func(t *testing.T) {
+		server := newServer(t)
+		neaktor := newNeaktor(server)
+
+		model := neaktor.MustGetModelByTitle("Заказ")
+		amountField := model.MustGetField("сумма")
+
+		query := model.Query().
+			Statuses(model.MustGetStatus("новый заказ"), model.MustGetStatus("выполнен")).
+			WhereRange(amountField, 1, nil).
+			OrderBy(amountField.Id, neaktor_api.SortDesc).
+			PageSize(25).
+			Limit(60)
+
+		tasks := query.MustGet()
+		if len(tasks) != 60 {
+			t.Fatalf("expected 60 tasks, got %d", len(tasks))
+		}
+		if first, last := tasks[0].MustGetField(amountField).Value, tasks[59].MustGetField(amountField).Value; first != float64(2) || last != float64(1) {
+			t.Fatalf("expected the tasks sorted by amount, got %v first and %v last", first, last)
+		}
+
+		if count, err := query.Count(); err != nil || count != 80 {
+			t.Fatalf("expected 80 matched tasks, got %d, %v", count, err)
+		}
+	})
+
+	t.Run("Options", func(t *testing.T) {
+		server := newServer(t)
+		neaktor := newNeaktor(server)
+
+		model := neaktor.MustGetModelByTitle("Заказ")
+		newStatus := model.MustGetStatus("новый заказ")
+		deliveryField := model.MustGetField("доставка")
+
+		// the model is loaded without the field types, an untyped field is a select one once its options are loaded
+		if count, err := model.Query().Statuses(newStatus).Where(deliveryField, "самовывоз").Count(); err != nil || count != 0 {
+			t.Fatalf("expected the option value sent as is for an untyped field, got %d, %v", count, err)
+		}
+		if fieldType := model.MustGetFieldType(deliveryField); fieldType != neaktor_api.FieldTypeSelect {
+			t.Fatalf("expected the select type by the options, got %q", fieldType)
+		}
+
+		if count, err := model.Query().Statuses(newStatus).Where(deliveryField, "самовывоз").Count(); err != nil || count != 60 {
+			t.Fatalf("expected 60 tasks by the option value, got %d, %v", count, err)
+		}
+
+		tasks := model.MustGetTasksByStatusAndFields(newStatus, []neaktor_api.TaskField{{ModelField: deliveryField, Value: "курьер"}})
+		if len(tasks) != 60 {
+			t.Fatalf("expected 60 tasks, got %d", len(tasks))
+		}
+		if field := tasks[0].MustGetField(deliveryField); field.Value != "o_courier" || field.Option != "курьер" {
+			t.Fatalf("expected the option id and value, got %+v", field)
+		}
+
+		task := tasks[0]
+		task.MustUpdateFields([]neaktor_api.TaskField{{ModelField: deliveryField, Value: "самовывоз"}})
+		if storedTask, _ := server.Task(task.GetId()); storedTask.Fields["f_delivery"] != "o_pickup" {
+			t.Fatalf("expected the option id to be stored, got %v", storedTask.Fields["f_delivery"])
+		}
+
+		customFieldRequests := 0
+		for _, request := range server.Requests() {
+			if request.Path == "/v1/customfields/f_delivery" {
+				customFieldRequests++
+			}
+		}
+		if customFieldRequests != 1 {
+			t.Fatalf("expected the options to be requested once, got %d", customFieldRequests)
+		}
+
+		if err := task.UpdateFields([]neaktor_api.TaskField{{ModelField: deliveryField, Value: "почта"}}); !errors.Is(err, neaktor_api.ErrModelCustomFieldOptionNotFound) {
+			t.Fatalf("expected ErrModelCustomFieldOptionNotFound, got %v", err)
+		}
+	})
+
+	t.Run("LazyOptions", func(t *testing.T) {
+		server := newServer(t)
+		neaktor := newNeaktor(server)
+
+		model := neaktor.MustGetModelByTitle("Заказ")
+		deliveryField := model.MustGetField("доставка")
+
+		if count, err := model.Query().Where(deliveryField, "o_pickup").Count(); err != nil || count != 60 {
+			t.Fatalf("expected the option id sent as is, got %d, %v", count, err)
+		}
+		if err := model.MustGetTaskById(2).UpdateFields([]neaktor_api.TaskField{{ModelField: deliveryField, Value: "o_pickup"}}); err != nil {
+			t.Fatal(err)
+		}
+
+		field := model.MustGetTaskById(1).MustGetField(deliveryField)
+		if field.Value != "o_courier" || len(field.Option) > 0 {
+			t.Fatalf("expected the option id only, got %+v", field)
+		}
+
+		customFieldRequests := func() (count int) {
+			for _, request := range server.Requests() {
+				if request.Path == "/v1/customfields/f_delivery" {
+					count++
+				}
+			}
+			return count
+		}
+		if count := customFieldRequests(); count != 0 {
+			t.Fatalf("expected no options requested by the filter, the write and the read, got %d requests", count)
+		}
+
+		canceledCtx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if _, err := field.OptionValueWithContext(canceledCtx); !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected the context passed to the options request, got %v", err)
+		}
+
+		if value, err := field.OptionValue(); err != nil || value != "курьер" {
+			t.Fatalf("expected the option value requested lazily, got %q, %v", value, err)
+		}
+		if count := customFieldRequests(); count != 1 {
+			t.Fatalf("expected the options requested by OptionValue, got %d requests", count)
+		}
+	})
+
+	t.Run("Types", func(t *testing.T) {
+		server := newServer(t)
+		neaktor := newNeaktor(server)
+
+		model := neaktor.MustGetModelByTitle("Заказ")
+		paidField := model.MustGetField("оплачен")
+		managerField := model.MustGetField("менеджер")
+		deliveryField := model.MustGetField("доставка")
+
+		if fieldType := model.MustGetFieldType(deliveryField); fieldType != neaktor_api.FieldTypeSelect {
+			t.Fatalf("expected the select type by the options, got %q", fieldType)
+		}
+		if options := model.MustGetFieldOptions(deliveryField); len(options) != 2 || options[1].GetValue() != "самовывоз" {
+			t.Fatalf("unexpected options %v", options)
+		}
+
+		task := model.MustGetTaskById(1)
+		if paid := task.MustGetField(paidField).Value; paid != time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC) {
+			t.Fatalf("expected the date to be decoded, got %#v", paid)
+		}
+		if manager := task.MustGetField(managerField).Value; manager != (neaktor_api.UserRef{Id: 7, Name: "Менеджер"}) {
+			t.Fatalf("expected the user to be decoded, got %#v", manager)
+		}
+		if _, err := task.GetCustomField(paidField); !errors.Is(err, neaktor_api.ErrTaskFieldTypeMismatch) {
+			t.Fatalf("expected ErrTaskFieldTypeMismatch, got %v", err)
+		}
+
+		task.MustUpdateFields([]neaktor_api.TaskField{
+			{ModelField: paidField, Value: time.Date(2024, 4, 2, 10, 0, 0, 0, time.UTC)},
+			{ModelField: managerField, Value: neaktor_api.UserRef{Id: 8}},
+		})
+		if storedTask, _ := server.Task(1); storedTask.Fields["f_paid"] != "02-04-2024T10:00:00" || storedTask.Fields["f_manager"] != float64(8) {
+			t.Fatalf("expected the values to be encoded, got %v", storedTask.Fields)
+		}
+
+		for _, request := range server.Requests() {
+			if request.Path == "/v1/customfields/f_paid" || request.Path == "/v1/customfields/f_manager" {
+				t.Fatalf("unexpected request of a typed field %s", request.Path)
+			}
+		}
+
+		badId := server.AddTask(neaktortest.Task{
+			ModelId:  "m1",
+			StatusId: "s_new",
+			Fields:   map[string]interface{}{"f_email": "bad@mail.ru", "f_paid": "2024-03-01"},
+		})
+		badTask, err := model.GetTaskById(badId)
+		if err != nil {
+			t.Fatalf("expected the task with a bad field to load, got %v", err)
+		}
+		if paid := badTask.MustGetField(paidField); paid.Value != "2024-03-01" {
+			t.Fatalf("expected the bad value kept as read, got %#v", paid.Value)
+		} else if _, err := paid.Time(); !errors.Is(err, neaktor_api.ErrTaskFieldTypeMismatch) {
+			t.Fatalf("expected ErrTaskFieldTypeMismatch from the accessor, got %v", err)
+		}
+	})
+
+	t.Run("Currency", func(t *testing.T) {
+		server := newServer(t)
+		neaktor := newNeaktor(server)
+
+		model := neaktor.MustGetModelByTitle("Заказ")
+		totalField := model.MustGetField("итого")
+		prepaidField := model.MustGetField("предоплата")
+
+		task := model.MustGetTaskById(1)
+		if total := task.MustGetField(totalField).Value; total != (neaktor_api.CurrencyFieldValue{Value: neaktor_api.MustParseDecimal("1500.5"), Currency: "RUB"}) {
+			t.Fatalf("expected the currency to be decoded, got %#v", total)
+		}
+		if prepaid, err := task.MustGetField(prepaidField).Currency(); err != nil || prepaid.Value.String() != "500" {
+			t.Fatalf("expected the currency to be decoded by the custom field type, got %#v, %v", prepaid, err)
+		}
+
+		task.MustUpdateFields([]neaktor_api.TaskField{{ModelField: totalField, Value: neaktor_api.CurrencyFieldValue{Value: neaktor_api.MustParseDecimal("2000"), Currency: "USD"}}})
+		createdTask := model.MustCreateTask(model.MustGetAssignee(model.MustGetStatus("новый заказ"), "Менеджер"), []neaktor_api.TaskField{
+			{ModelField: totalField, Value: &neaktor_api.CurrencyFieldValue{Value: neaktor_api.MustParseDecimal("10"), Currency: "RUB"}},
+		})
+
+		if storedTask, _ := server.Task(1); fmt.Sprint(storedTask.Fields["f_total"]) != "map[currency:USD value:2000]" {
+			t.Fatalf("unexpected stored currency %v", storedTask.Fields["f_total"])
+		}
+		if total := createdTask.MustGetField(totalField).Value; total != (neaktor_api.CurrencyFieldValue{Value: neaktor_api.MustParseDecimal("10"), Currency: "RUB"}) {
+			t.Fatalf("unexpected created currency %#v", total)
+		}
+	})
+
+	t.Run("TypedModel", func(t *testing.T) {
+		type Order struct {
+			Email    string `neaktor:"email"`
+			Delivery string `neaktor:"доставка,omitempty,optional"`
+		}
+
+		server := newServer(t)
+		neaktor := newNeaktor(server)
+
+		model := neaktor.MustGetModelByTitle("Заказ")
+		model.MustGetFieldOptions(model.MustGetField("доставка"))
+
+		orders := neaktor_api.NewTypedModel[Order](model)
+		id := orders.MustCreate(Order{Email: "first@mail.ru", Delivery: "курьер"})
+
+		createdRequests := len(server.Requests())
+		orders.MustUpdate(id, Order{Email: "second@mail.ru"})
+		taskPath := fmt.Sprintf("/v1/tasks/%d", id)
+		if requests := server.Requests()[createdRequests:]; len(requests) != 1 || requests[0].Method != http.MethodPut || requests[0].Path != taskPath {
+			t.Fatalf("expected the task updated by id without reading it, got %v", requests)
+		}
+
+		if order := orders.MustGetById(id); order != (Order{Email: "second@mail.ru", Delivery: "курьер"}) {
+			t.Fatalf("unexpected order %+v", order)
+		}
+		if storedTask, _ := server.Task(id); storedTask.Fields["f_delivery"] != "o_courier" {
+			t.Fatalf("expected the option id to be stored, got %v", storedTask.Fields["f_delivery"])
+		}
+
+		iterator := orders.IterateByFields([]neaktor_api.TaskField{{ModelField: model.MustGetField("email"), Value: "second@mail.ru"}})
+		var ids []int
+		for iterator.Next() {
+			ids = append(ids, iterator.Task().GetId())
+		}
+		if err := iterator.Err(); err != nil || len(ids) != 1 || ids[0] != id {
+			t.Fatalf("expected the updated task only, got %v, %v", ids, err)
+		}
+	})
+
+	t.Run("Faults", func(t *testing.T) {
+		server := newServer(t)
+		server.InjectFault(neaktortest.Fault{Path: "/v1/taskmodels", StatusCode: http.StatusTooManyRequests, RetryAfter: "0", Times: 1})
+		server.InjectFault(neaktortest.Fault{Method: http.MethodGet, Path: "/v1/tasks", StatusCode: http.StatusInternalServerError, Times: 1})
+
+		neaktor := newNeaktor(server, neaktor_api.WithRetryPolicy(neaktor_api.RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond}))
+
+		model := neaktor.MustGetModelByTitle("Заказ")
+		if _, err := model.GetTaskById(1); err != nil {
+			t.Fatal(err)
+		}
+
+		server.InjectFault(neaktortest.Fault{StatusCode: http.StatusInternalServerError, Times: 2})
+		if _, err := model.GetTaskById(1); !errors.Is(err, neaktor_api.ErrCode500) {
+			t.Fatalf("expected ErrCode500, got %v", err)
+		}
+	})
+
+	t.Run("OauthToken", func(t *testing.T) {
+		server := newServer(t)
+		server.SetOauthClient("c1", "s1", "r0")
+
+		tokenStore := neaktor_api.NewFileTokenStore(t.TempDir() + "/token.json")
+
+		neaktor := neaktor_api.NewNeaktorByRefreshToken(*requrl.NewRequest(), "r0", 6000,
+			neaktor_api.WithBaseUrl(server.URL),
+			neaktor_api.WithHttpDoer(neaktor_api.NewNetHttpDoer(server.Client())),
+			neaktor_api.WithClientCredentials("c1", "s1"),
+			neaktor_api.WithTokenStore(tokenStore))
+
+		if _, err := neaktor.GetModelByTitle("Заказ"); err != nil {
+			t.Fatal(err)
+		}
+
+		token, err := tokenStore.Load(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if token.RefreshToken != server.RefreshToken() {
+			t.Fatalf("rotated refresh token is not stored: %q", token.RefreshToken)
+		}
+	})
+}